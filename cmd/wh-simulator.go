@@ -50,6 +50,8 @@ func main() {
 		log.Fatalf("unable to read files from mapping directory")
 	}
 
+	srv.Scheduler().Start()
+
 	go func() {
 		port := cfg.Port
 		log.Printf("opening server on port %d\n", port)
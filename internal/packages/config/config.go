@@ -10,12 +10,29 @@ import (
 )
 
 type Config struct {
-	Port         int
-	Mapping      string
-	SkipFSEvents bool
+	Port             int
+	Mapping          string
+	SkipFSEvents     bool
+	UnmatchedCode    int
+	UnmatchedBody    string
+	UnmatchedHeaders map[string]string
+	JournalSize      int
+	WebHookClient    WebHookClient
+}
+
+type WebHookClient struct {
+	Timeout            int
+	InsecureSkipVerify bool
+	CACert             string
+	ClientCert         string
+	ClientKey          string
+	Proxy              string
 }
 
 const DefaultMapping = "/mapping"
+const DefaultUnmatchedCode = 404
+const DefaultJournalSize = 1000
+const DefaultWebHookTimeout = 30000
 
 func ParseConfig() Config {
 	c := Config{}
@@ -27,12 +44,61 @@ func ParseConfig() Config {
 		fmt.Sprintf("Location of file system where mapping files are stored. (ENV_VAR - MAPPING). Default: %s", DefaultMapping),
 	)
 
+	unmatchedCode := flag.Int(
+		"unmatched-code",
+		0,
+		fmt.Sprintf("Status code returned when no mapping matches the request (ENV_VAR - UNMATCHED_CODE). Default %d", DefaultUnmatchedCode),
+	)
+	unmatchedBody := flag.String(
+		"unmatched-body",
+		"",
+		"Body returned when no mapping matches the request (ENV_VAR - UNMATCHED_BODY). Default: near-miss diagnostic report",
+	)
+	unmatchedHeaders := flag.String(
+		"unmatched-headers",
+		"",
+		"Headers returned when no mapping matches the request separated by ; (ENV_VAR - UNMATCHED_HEADERS)",
+	)
+
+	journalSize := flag.Int(
+		"journal-size",
+		0,
+		fmt.Sprintf("Number of received requests kept in request journal (ENV_VAR - JOURNAL_SIZE). Default %d", DefaultJournalSize),
+	)
+
+	webHookTimeout := flag.Int(
+		"webhook-timeout",
+		0,
+		fmt.Sprintf("Timeout in milliseconds for webhook requests (ENV_VAR - WEBHOOK_TIMEOUT). Default %d", DefaultWebHookTimeout),
+	)
+	webHookInsecure := flag.Bool(
+		"webhook-insecure",
+		false,
+		"Skip TLS certificate verification for webhook requests (ENV_VAR - WEBHOOK_INSECURE). Default false",
+	)
+	webHookCACert := flag.String("webhook-ca-cert", "", "PEM bundle of trusted CAs for webhook requests (ENV_VAR - WEBHOOK_CA_CERT)")
+	webHookClientCert := flag.String("webhook-client-cert", "", "PEM client certificate for webhook mTLS (ENV_VAR - WEBHOOK_CLIENT_CERT)")
+	webHookClientKey := flag.String("webhook-client-key", "", "PEM client key for webhook mTLS (ENV_VAR - WEBHOOK_CLIENT_KEY)")
+	webHookProxy := flag.String("webhook-proxy", "", "HTTP proxy used for webhook requests (ENV_VAR - WEBHOOK_PROXY)")
+
 	flag.Parse()
 
 	// Set to config
 	c.Port = getPort(port)
 	c.Mapping = getMapping(location)
 	c.SkipFSEvents = getUseFSEvents()
+	c.UnmatchedCode = getInt(unmatchedCode, "UNMATCHED_CODE", DefaultUnmatchedCode)
+	c.UnmatchedBody = getString(unmatchedBody, "UNMATCHED_BODY", "")
+	c.UnmatchedHeaders = parseHeaders(getString(unmatchedHeaders, "UNMATCHED_HEADERS", ""))
+	c.JournalSize = getInt(journalSize, "JOURNAL_SIZE", DefaultJournalSize)
+	c.WebHookClient = WebHookClient{
+		Timeout:            getInt(webHookTimeout, "WEBHOOK_TIMEOUT", DefaultWebHookTimeout),
+		InsecureSkipVerify: getBool(webHookInsecure, "WEBHOOK_INSECURE"),
+		CACert:             getString(webHookCACert, "WEBHOOK_CA_CERT", ""),
+		ClientCert:         getString(webHookClientCert, "WEBHOOK_CLIENT_CERT", ""),
+		ClientKey:          getString(webHookClientKey, "WEBHOOK_CLIENT_KEY", ""),
+		Proxy:              getString(webHookProxy, "WEBHOOK_PROXY", ""),
+	}
 
 	return c
 }
@@ -72,3 +138,57 @@ func getUseFSEvents() bool {
 	shouldSkip := os.Getenv("SKIP_FS_EVENTS")
 	return strings.ToLower(shouldSkip) == "true"
 }
+
+func getInt(cliValue *int, envName string, defaultValue int) int {
+	if *cliValue != 0 {
+		return *cliValue
+	}
+
+	envValue := os.Getenv(envName)
+	if envValue != "" {
+		value, err := strconv.Atoi(envValue)
+		if err != nil {
+			log.Fatalf("unable to parse %s from env variable %s\n", envName, envValue)
+		}
+
+		return value
+	}
+
+	return defaultValue
+}
+
+func getString(cliValue *string, envName string, defaultValue string) string {
+	if *cliValue != "" {
+		return *cliValue
+	}
+
+	envValue, found := os.LookupEnv(envName)
+	if found {
+		return envValue
+	}
+
+	return defaultValue
+}
+
+func getBool(cliValue *bool, envName string) bool {
+	if *cliValue {
+		return true
+	}
+
+	return strings.ToLower(os.Getenv(envName)) == "true"
+}
+
+func parseHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+
+	for _, header := range strings.Split(value, ";") {
+		name, headerValue, found := strings.Cut(header, ":")
+		if !found {
+			continue
+		}
+
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+	}
+
+	return headers
+}
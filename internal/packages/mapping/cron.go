@@ -0,0 +1,159 @@
+package mapping
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type cronField struct {
+	min int
+	max int
+}
+
+var cronFields = [...]cronField{
+	{min: 0, max: 59},
+	{min: 0, max: 23},
+	{min: 1, max: 31},
+	{min: 1, max: 12},
+	{min: 0, max: 6},
+}
+
+type cronExpression struct {
+	minutes  []bool
+	hours    []bool
+	days     []bool
+	months   []bool
+	weekdays []bool
+	anyDay   bool
+	anyWeek  bool
+}
+
+func parseCron(expression string) (*cronExpression, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression %q must have %d fields", expression, len(cronFields))
+	}
+
+	parsed := make([][]bool, len(fields))
+
+	for index, field := range fields {
+		values, err := parseCronField(field, cronFields[index])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+		}
+
+		parsed[index] = values
+	}
+
+	// Sunday can be written as 7
+	if parsed[4][7] {
+		parsed[4][0] = true
+	}
+
+	return &cronExpression{
+		minutes:  parsed[0],
+		hours:    parsed[1],
+		days:     parsed[2],
+		months:   parsed[3],
+		weekdays: parsed[4],
+		anyDay:   strings.HasPrefix(fields[2], "*"),
+		anyWeek:  strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(field string, bounds cronField) ([]bool, error) {
+	max := bounds.max
+	if bounds.max == 6 {
+		max = 7
+	}
+
+	values := make([]bool, max+1)
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			parsedStep, err := strconv.Atoi(stepPart)
+			if err != nil || parsedStep < 1 {
+				return nil, fmt.Errorf("invalid step %s", stepPart)
+			}
+
+			step = parsedStep
+		}
+
+		start, end := bounds.min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+
+			parsedFrom, err := strconv.Atoi(from)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %s", from)
+			}
+
+			start, end = parsedFrom, parsedFrom
+			if isRange {
+				end, err = strconv.Atoi(to)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value %s", to)
+				}
+			} else if hasStep {
+				end = max
+			}
+		}
+
+		if start < bounds.min || end > max || start > end {
+			return nil, fmt.Errorf("value %s out of range %d-%d", rangePart, bounds.min, max)
+		}
+
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+
+	return values, nil
+}
+
+func (c *cronExpression) matchesDay(t time.Time) bool {
+	day := c.days[t.Day()]
+	weekday := c.weekdays[int(t.Weekday())]
+
+	if c.anyDay || c.anyWeek {
+		return day && weekday
+	}
+
+	return day || weekday
+}
+
+func (c *cronExpression) next(from time.Time) time.Time {
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !c.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,88 @@
+package mapping
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	from := time.Date(2024, 10, 27, 20, 34, 58, 0, time.UTC) // Sunday
+
+	testCases := []struct {
+		name     string
+		schedule ScheduleDefinition
+		next     time.Time
+	}{
+		{
+			name:     "adds interval",
+			schedule: ScheduleDefinition{Interval: 1500},
+			next:     from.Add(1500 * time.Millisecond),
+		},
+		{
+			name:     "every minute",
+			schedule: ScheduleDefinition{Cron: "* * * * *"},
+			next:     time.Date(2024, 10, 27, 20, 35, 0, 0, time.UTC),
+		},
+		{
+			name:     "every fifteen minutes",
+			schedule: ScheduleDefinition{Cron: "*/15 * * * *"},
+			next:     time.Date(2024, 10, 27, 20, 45, 0, 0, time.UTC),
+		},
+		{
+			name:     "list of hours",
+			schedule: ScheduleDefinition{Cron: "0 8,12 * * *"},
+			next:     time.Date(2024, 10, 28, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekdays range",
+			schedule: ScheduleDefinition{Cron: "30 9 * * 1-5"},
+			next:     time.Date(2024, 10, 28, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "first day of month",
+			schedule: ScheduleDefinition{Cron: "0 0 1 * *"},
+			next:     time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day of month or day of week",
+			schedule: ScheduleDefinition{Cron: "0 0 15 * 3"},
+			next:     time.Date(2024, 10, 30, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "sunday as seven",
+			schedule: ScheduleDefinition{Cron: "0 21 * * 7"},
+			next:     time.Date(2024, 10, 27, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "leap day",
+			schedule: ScheduleDefinition{Cron: "0 0 29 2 *"},
+			next:     time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			require.NoError(t, test.schedule.Compile())
+			require.Equal(t, test.next, test.schedule.Next(from))
+		})
+	}
+}
+
+func TestScheduleCompile(t *testing.T) {
+	invalid := []ScheduleDefinition{
+		{},
+		{Interval: -1},
+		{Cron: "* * * * *", Interval: 1000},
+		{Cron: "* * * *"},
+		{Cron: "60 * * * *"},
+		{Cron: "* * 0 * *"},
+		{Cron: "*/0 * * * *"},
+		{Cron: "5-1 * * * *"},
+		{Cron: "a * * * *"},
+	}
+
+	for _, schedule := range invalid {
+		require.Error(t, schedule.Compile(), schedule.Cron)
+	}
+}
@@ -1,6 +1,12 @@
 package mapping
 
-import "strings"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const regexOperator = "$regex"
 
 var supportedExtensions = [...]string{".whs", ".json"}
 
@@ -12,3 +18,48 @@ func HasMappingFileExtension(name string) bool {
 	}
 	return false
 }
+
+func compilePathPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+func compileRegexOperators(value any, compiled map[string]*regexp.Regexp) error {
+	switch t := value.(type) {
+	case map[string]any:
+		{
+			for k, v := range t {
+				if k == regexOperator {
+					pattern, ok := v.(string)
+					if !ok {
+						return fmt.Errorf("%s expects string pattern", regexOperator)
+					}
+
+					expression, err := regexp.Compile(pattern)
+					if err != nil {
+						return err
+					}
+
+					compiled[pattern] = expression
+					continue
+				}
+
+				err := compileRegexOperators(v, compiled)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+	case []any:
+		{
+			for _, elem := range t {
+				err := compileRegexOperators(elem, compiled)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -2,18 +2,29 @@ package mapping
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/djordjev/webhook-simulator/internal/packages/config"
+	"github.com/google/uuid"
 	"io/fs"
 	"log"
+	"slices"
+	"strings"
 	"sync"
 )
 
 const Root = "."
 
+var ErrMappingNotFound = errors.New("mapping not found")
+var ErrMappingExists = errors.New("mapping with the same id already exists")
+var ErrMappingReadOnly = errors.New("mapping is defined in mapping directory and can't be changed")
+var ErrInvalidMapping = errors.New("invalid mapping")
+
 type mapping struct {
 	config     config.Config
 	fileSystem fs.FS
+	files      []Flow
+	registered []Flow
 	mappings   []Flow
 	lock       sync.Mutex
 }
@@ -24,7 +35,7 @@ func (m *mapping) Refresh() (err error) {
 
 	result := make(chan *Flow)
 	counter := 0
-	m.mappings = make([]Flow, 0)
+	mappings := make([]Flow, 0)
 
 	err = fs.WalkDir(m.fileSystem, ".", func(path string, d fs.DirEntry, err error) error {
 		if path == Root {
@@ -48,13 +59,28 @@ func (m *mapping) Refresh() (err error) {
 	for i := 0; i < counter; i++ {
 		flow := <-result
 		if flow != nil {
-			m.mappings = append(m.mappings, *flow)
+			mappings = append(mappings, *flow)
 		}
 	}
 
+	m.files = mappings
+	m.merge()
+
 	return
 }
 
+func (m *mapping) merge() {
+	mappings := make([]Flow, 0, len(m.files)+len(m.registered))
+	mappings = append(mappings, m.files...)
+	mappings = append(mappings, m.registered...)
+
+	slices.SortStableFunc(mappings, func(a Flow, b Flow) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	m.mappings = mappings
+}
+
 func (m *mapping) readMapping(path string, result chan<- *Flow) {
 	log.Println(fmt.Sprintf("reading file %s", path))
 
@@ -74,9 +100,173 @@ func (m *mapping) readMapping(path string, result chan<- *Flow) {
 		log.Println(fmt.Sprintf("unable to parse content of file %s", path))
 		return
 	}
+
+	if flow != nil && flow.ID == "" {
+		flow.ID = path
+	}
+
+	if flow != nil {
+		err = prepare(flow)
+		if err != nil {
+			log.Println(fmt.Sprintf("invalid mapping in file %s: %s", path, err))
+			flow = nil
+			return
+		}
+	}
+}
+
+func prepare(flow *Flow) error {
+	err := validateWebHooks(flow.AllWebHooks())
+	if err != nil {
+		return err
+	}
+
+	if flow.ResponseMode != "" && !slices.Contains(responseModes, flow.ResponseMode) {
+		return fmt.Errorf("unsupported response mode %s", flow.ResponseMode)
+	}
+
+	if flow.Schedule != nil {
+		err = flow.Schedule.Compile()
+		if err != nil {
+			return err
+		}
+	}
+
+	if flow.Request == nil {
+		return nil
+	}
+
+	if flow.Response == nil && len(flow.Responses) == 0 {
+		return errors.New("mapping with request requires response")
+	}
+
+	return flow.Request.Compile()
+}
+
+func validateWebHooks(hooks []*WebHookDefinition) error {
+	for _, hook := range hooks {
+		if hook.Signing != nil {
+			err := hook.Signing.Validate()
+			if err != nil {
+				return err
+			}
+		}
+
+		if hook.Retry != nil {
+			err := hook.Retry.Validate()
+			if err != nil {
+				return err
+			}
+		}
+
+		for i := range hook.Then {
+			err := validateWebHooks([]*WebHookDefinition{&hook.Then[i]})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *mapping) GetMapping(id string) (Flow, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	index := slices.IndexFunc(m.mappings, func(flow Flow) bool { return flow.ID == id })
+	if index < 0 {
+		return Flow{}, false
+	}
+
+	return m.mappings[index], true
+}
+
+func (m *mapping) AddMapping(flow Flow) (Flow, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if flow.ID == "" {
+		flow.ID = uuid.NewString()
+	}
+
+	if slices.ContainsFunc(m.mappings, func(existing Flow) bool { return existing.ID == flow.ID }) {
+		return Flow{}, ErrMappingExists
+	}
+
+	err := prepare(&flow)
+	if err != nil {
+		return Flow{}, fmt.Errorf("%w: %w", ErrInvalidMapping, err)
+	}
+
+	m.registered = append(m.registered, flow)
+	m.merge()
+
+	return flow, nil
+}
+
+func (m *mapping) UpdateMapping(id string, flow Flow) (Flow, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	index, err := m.registeredIndex(id)
+	if err != nil {
+		return Flow{}, err
+	}
+
+	flow.ID = id
+
+	err = prepare(&flow)
+	if err != nil {
+		return Flow{}, fmt.Errorf("%w: %w", ErrInvalidMapping, err)
+	}
+
+	m.registered[index] = flow
+	m.merge()
+
+	return flow, nil
+}
+
+func (m *mapping) DeleteMapping(id string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	index, err := m.registeredIndex(id)
+	if err != nil {
+		return err
+	}
+
+	m.registered = slices.Delete(m.registered, index, index+1)
+	m.merge()
+
+	return nil
+}
+
+func (m *mapping) ClearMappings() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.registered = make([]Flow, 0)
+	m.merge()
+}
+
+func (m *mapping) registeredIndex(id string) (int, error) {
+	index := slices.IndexFunc(m.registered, func(flow Flow) bool { return flow.ID == id })
+	if index >= 0 {
+		return index, nil
+	}
+
+	if slices.ContainsFunc(m.files, func(flow Flow) bool { return flow.ID == id }) {
+		return -1, ErrMappingReadOnly
+	}
+
+	return -1, ErrMappingNotFound
 }
 
 func (m *mapping) GetMappings() []Flow {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	return m.mappings
 }
 
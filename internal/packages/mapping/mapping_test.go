@@ -96,6 +96,41 @@ var secondPair = dataPair{
 	},
 }
 
+var patternPair = dataPair{
+	json: `
+		{
+			"request": {
+				"method": "GET",
+				"pathPattern": "/(v1|v2beta)/users/(?P<id>[0-9]+)"
+			},
+			"response": {
+				"code": 200,
+				"body": { "id": "${{path.id}}" }
+			}
+		}
+	`,
+	flow: Flow{
+		Request: compiled(&RequestDefinition{
+			Method:      "GET",
+			PathPattern: "/(v1|v2beta)/users/(?P<id>[0-9]+)",
+		}),
+		Response: &ResponseDefinition{
+			Code: 200,
+			Body: map[string]any{"id": "${{path.id}}"},
+		},
+	},
+}
+
+func withID(flow Flow, id string) Flow {
+	flow.ID = id
+	return flow
+}
+
+func compiled(definition *RequestDefinition) *RequestDefinition {
+	_ = definition.Compile()
+	return definition
+}
+
 func TestRefresh(t *testing.T) {
 	testCases := []struct {
 		name   string
@@ -112,7 +147,7 @@ func TestRefresh(t *testing.T) {
 			fs: fstest.MapFS{
 				"file1.whs": {Data: []byte(firstPair.json)},
 			},
-			result: []Flow{firstPair.flow},
+			result: []Flow{withID(firstPair.flow, "file1.whs")},
 		},
 		{
 			name: "does not read files that are not with .whs extension",
@@ -120,7 +155,7 @@ func TestRefresh(t *testing.T) {
 				"file1.whs":     {Data: []byte(firstPair.json)},
 				"file2.not-whs": {Data: []byte(secondPair.json)},
 			},
-			result: []Flow{firstPair.flow},
+			result: []Flow{withID(firstPair.flow, "file1.whs")},
 		},
 		{
 			name: "ignores non parsable files",
@@ -128,7 +163,7 @@ func TestRefresh(t *testing.T) {
 				"file1.whs": {Data: []byte(firstPair.json)},
 				"file2.whs": {Data: []byte("{ wrong: json")},
 			},
-			result: []Flow{firstPair.flow},
+			result: []Flow{withID(firstPair.flow, "file1.whs")},
 		},
 		{
 			name: "reads two correct files",
@@ -136,7 +171,57 @@ func TestRefresh(t *testing.T) {
 				"file1.whs": {Data: []byte(firstPair.json)},
 				"file2.whs": {Data: []byte(secondPair.json)},
 			},
-			result: []Flow{firstPair.flow, secondPair.flow},
+			result: []Flow{withID(firstPair.flow, "file1.whs"), withID(secondPair.flow, "file2.whs")},
+		},
+		{
+			name: "compiles path pattern",
+			fs: fstest.MapFS{
+				"file1.whs": {Data: []byte(patternPair.json)},
+			},
+			result: []Flow{withID(patternPair.flow, "file1.whs")},
+		},
+		{
+			name: "keeps id defined in file",
+			fs: fstest.MapFS{
+				"file1.whs": {Data: []byte(`{ "id": "custom", "priority": 10, "request": { "method": "GET", "path": "/" }, "response": { "code": 204 } }`)},
+			},
+			result: []Flow{
+				{
+					ID:       "custom",
+					Priority: 10,
+					Request:  &RequestDefinition{Method: "GET", Path: "/"},
+					Response: &ResponseDefinition{Code: 204},
+				},
+			},
+		},
+		{
+			name: "orders mappings by id",
+			fs: fstest.MapFS{
+				"b.whs": {Data: []byte(secondPair.json)},
+				"a.whs": {Data: []byte(firstPair.json)},
+				"c.whs": {Data: []byte(firstPair.json)},
+			},
+			result: []Flow{
+				withID(firstPair.flow, "a.whs"),
+				withID(secondPair.flow, "b.whs"),
+				withID(firstPair.flow, "c.whs"),
+			},
+		},
+		{
+			name: "ignores files with invalid path pattern",
+			fs: fstest.MapFS{
+				"file1.whs": {Data: []byte(firstPair.json)},
+				"file2.whs": {Data: []byte(`{ "request": { "method": "GET", "pathPattern": "/users/(" } }`)},
+			},
+			result: []Flow{withID(firstPair.flow, "file1.whs")},
+		},
+		{
+			name: "ignores files with request but without response",
+			fs: fstest.MapFS{
+				"file1.whs": {Data: []byte(firstPair.json)},
+				"file2.whs": {Data: []byte(`{ "request": { "method": "GET", "path": "/" }, "responses": [] }`)},
+			},
+			result: []Flow{withID(firstPair.flow, "file1.whs")},
 		},
 	}
 
@@ -146,8 +231,164 @@ func TestRefresh(t *testing.T) {
 
 			_ = testMapping.Refresh()
 
-			require.ElementsMatch(t, testMapping.GetMappings(), test.result)
+			require.Equal(t, test.result, testMapping.GetMappings())
 		})
 	}
 
 }
+
+func TestRegisteredMappings(t *testing.T) {
+	fileSystem := fstest.MapFS{
+		"file1.whs": {Data: []byte(firstPair.json)},
+	}
+
+	registered := Flow{
+		ID:       "registered",
+		Request:  &RequestDefinition{Method: "GET", Path: "/registered"},
+		Response: &ResponseDefinition{Code: 200},
+	}
+
+	t.Run("adds mapping that survives refresh", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+		_ = testMapping.Refresh()
+
+		created, err := testMapping.AddMapping(registered)
+		require.NoError(t, err)
+		require.Equal(t, registered, created)
+
+		_ = testMapping.Refresh()
+
+		require.Equal(t, []Flow{withID(firstPair.flow, "file1.whs"), registered}, testMapping.GetMappings())
+
+		found, ok := testMapping.GetMapping("registered")
+		require.True(t, ok)
+		require.Equal(t, registered, found)
+	})
+
+	t.Run("generates id when missing", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+
+		created, err := testMapping.AddMapping(Flow{Request: &RequestDefinition{Method: "GET", Path: "/"}, Response: &ResponseDefinition{Code: 200}})
+
+		require.NoError(t, err)
+		require.NotEmpty(t, created.ID)
+	})
+
+	t.Run("does not add mapping with existing id", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+		_ = testMapping.Refresh()
+
+		_, err := testMapping.AddMapping(withID(registered, "file1.whs"))
+
+		require.ErrorIs(t, err, ErrMappingExists)
+	})
+
+	t.Run("does not add mapping with invalid path pattern", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+
+		_, err := testMapping.AddMapping(Flow{Request: &RequestDefinition{PathPattern: "/users/("}})
+
+		require.ErrorIs(t, err, ErrInvalidMapping)
+	})
+
+	t.Run("does not add mapping with invalid regex operator", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+
+		_, err := testMapping.AddMapping(Flow{Request: &RequestDefinition{Body: map[string]any{"id": map[string]any{"$regex": "ord_("}}}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+
+		_, err = testMapping.AddMapping(Flow{Request: &RequestDefinition{Headers: map[string]any{"X-Id": []any{map[string]any{"$not": map[string]any{"$regex": "["}}}}}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+
+		_, err = testMapping.AddMapping(Flow{Request: &RequestDefinition{Body: map[string]any{"id": map[string]any{"$regex": float64(1)}}}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+	})
+
+	t.Run("does not add mapping with invalid signing", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+
+		_, err := testMapping.AddMapping(Flow{WebHook: &WebHookDefinition{Signing: &SigningDefinition{Secret: "secret", Algorithm: "md5"}}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+
+		_, err = testMapping.AddMapping(Flow{WebHook: &WebHookDefinition{Signing: &SigningDefinition{Algorithm: SHA256}}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+	})
+
+	t.Run("does not add mapping with invalid retry backoff", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+
+		_, err := testMapping.AddMapping(Flow{WebHook: &WebHookDefinition{Retry: &RetryDefinition{Backoff: "exponentail"}}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+
+		_, err = testMapping.AddMapping(Flow{WebHooks: []WebHookDefinition{{Then: []WebHookDefinition{{Retry: &RetryDefinition{Backoff: "linear"}}}}}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+	})
+
+	t.Run("does not add mapping with invalid response mode", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+
+		_, err := testMapping.AddMapping(Flow{ResponseMode: "shuffle"})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+	})
+
+	t.Run("does not add or update mapping with request but without response", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+		_, _ = testMapping.AddMapping(registered)
+
+		_, err := testMapping.AddMapping(Flow{Request: &RequestDefinition{Method: "GET", Path: "/x"}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+
+		_, err = testMapping.AddMapping(Flow{Request: &RequestDefinition{Method: "GET", Path: "/x"}, Responses: []ResponseDefinition{}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+
+		_, err = testMapping.UpdateMapping("registered", Flow{Request: &RequestDefinition{Method: "GET", Path: "/x"}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+	})
+
+	t.Run("updates registered mapping", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+		_, _ = testMapping.AddMapping(registered)
+
+		updated, err := testMapping.UpdateMapping("registered", Flow{Request: &RequestDefinition{Method: "POST", Path: "/updated"}, Response: &ResponseDefinition{Code: 201}})
+		require.NoError(t, err)
+
+		found, _ := testMapping.GetMapping("registered")
+		require.Equal(t, updated, found)
+		require.Equal(t, "/updated", found.Request.Path)
+	})
+
+	t.Run("does not update or delete mapping from file", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+		_ = testMapping.Refresh()
+
+		_, err := testMapping.UpdateMapping("file1.whs", registered)
+		require.ErrorIs(t, err, ErrMappingReadOnly)
+
+		err = testMapping.DeleteMapping("file1.whs")
+		require.ErrorIs(t, err, ErrMappingReadOnly)
+	})
+
+	t.Run("does not update or delete missing mapping", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+
+		_, err := testMapping.UpdateMapping("missing", registered)
+		require.ErrorIs(t, err, ErrMappingNotFound)
+
+		err = testMapping.DeleteMapping("missing")
+		require.ErrorIs(t, err, ErrMappingNotFound)
+	})
+
+	t.Run("deletes and clears registered mappings", func(t *testing.T) {
+		testMapping := NewMapping(config.Config{}, fileSystem)
+		_ = testMapping.Refresh()
+		_, _ = testMapping.AddMapping(registered)
+		_, _ = testMapping.AddMapping(withID(registered, "another"))
+
+		err := testMapping.DeleteMapping("registered")
+		require.NoError(t, err)
+		require.Len(t, testMapping.GetMappings(), 2)
+
+		testMapping.ClearMappings()
+		require.Equal(t, []Flow{withID(firstPair.flow, "file1.whs")}, testMapping.GetMappings())
+	})
+}
@@ -1,36 +1,269 @@
 package mapping
 
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
 type Mapper interface {
 	Refresh() error
 	GetMappings() []Flow
+	GetMapping(id string) (Flow, bool)
+	AddMapping(flow Flow) (Flow, error)
+	UpdateMapping(id string, flow Flow) (Flow, error)
+	DeleteMapping(id string) error
+	ClearMappings()
 }
 
 type RequestDefinition struct {
-	Method  string            `json:"method"`
-	Path    string            `json:"path"`
-	Body    map[string]any    `json:"body"`
-	Headers map[string]string `json:"headers"`
+	Method      string         `json:"method"`
+	Path        string         `json:"path"`
+	PathPattern string         `json:"pathPattern"`
+	Query       map[string]any `json:"query"`
+	Body        map[string]any `json:"body"`
+	Headers     map[string]any `json:"headers"`
+
+	pathRegexp     *regexp.Regexp
+	operatorRegexp map[string]*regexp.Regexp
 }
 
+func (r *RequestDefinition) Compile() error {
+	compiled := make(map[string]*regexp.Regexp)
+
+	err := compileRegexOperators(r.Body, compiled)
+	if err != nil {
+		return err
+	}
+
+	err = compileRegexOperators(r.Headers, compiled)
+	if err != nil {
+		return err
+	}
+
+	r.operatorRegexp = nil
+	if len(compiled) > 0 {
+		r.operatorRegexp = compiled
+	}
+
+	if r.PathPattern == "" {
+		r.pathRegexp = nil
+		return nil
+	}
+
+	pathRegexp, err := compilePathPattern(r.PathPattern)
+	if err != nil {
+		return err
+	}
+
+	r.pathRegexp = pathRegexp
+	return nil
+}
+
+func (r *RequestDefinition) PathRegexp() *regexp.Regexp {
+	if r.pathRegexp != nil || r.PathPattern == "" {
+		return r.pathRegexp
+	}
+
+	compiled, err := compilePathPattern(r.PathPattern)
+	if err != nil {
+		return nil
+	}
+
+	return compiled
+}
+
+func (r *RequestDefinition) OperatorRegexp(pattern string) *regexp.Regexp {
+	if compiled, found := r.operatorRegexp[pattern]; found {
+		return compiled
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+
+	return compiled
+}
+
+const XMLFormat = "xml"
+
 type ResponseDefinition struct {
 	Code           int               `json:"code"`
 	Delay          int               `json:"delay"`
 	IncludeRequest bool              `json:"includeRequest"`
 	Headers        map[string]string `json:"headers"`
 	Body           map[string]any    `json:"body"`
+	Format         string            `json:"format"`
+	Template       string            `json:"template"`
+}
+
+const FixedBackoff = "fixed"
+const ExponentialBackoff = "exponential"
+
+var backoffs = []string{FixedBackoff, ExponentialBackoff}
+
+type RetryDefinition struct {
+	MaxAttempts  int    `json:"maxAttempts"`
+	Backoff      string `json:"backoff"`
+	Interval     int    `json:"interval"`
+	MaxInterval  int    `json:"maxInterval"`
+	Jitter       int    `json:"jitter"`
+	SuccessCodes []int  `json:"successCodes"`
+}
+
+func (r *RetryDefinition) Validate() error {
+	if r.Backoff != "" && !slices.Contains(backoffs, r.Backoff) {
+		return fmt.Errorf("unsupported retry backoff %s", r.Backoff)
+	}
+
+	return nil
+}
+
+const SHA1 = "sha1"
+const SHA256 = "sha256"
+const SHA512 = "sha512"
+
+const HexEncoding = "hex"
+const Base64Encoding = "base64"
+
+var signingAlgorithms = []string{SHA1, SHA256, SHA512}
+var signingEncodings = []string{HexEncoding, Base64Encoding}
+
+type SigningDefinition struct {
+	Algorithm       string `json:"algorithm"`
+	Secret          string `json:"secret"`
+	Header          string `json:"header"`
+	Format          string `json:"format"`
+	Encoding        string `json:"encoding"`
+	Timestamp       bool   `json:"timestamp"`
+	TimestampHeader string `json:"timestampHeader"`
+}
+
+func (s *SigningDefinition) Validate() error {
+	if s.Secret == "" {
+		return errors.New("missing signing secret")
+	}
+
+	if s.Algorithm != "" && !slices.Contains(signingAlgorithms, s.Algorithm) {
+		return fmt.Errorf("unsupported signing algorithm %s", s.Algorithm)
+	}
+
+	if s.Encoding != "" && !slices.Contains(signingEncodings, s.Encoding) {
+		return fmt.Errorf("unsupported signature encoding %s", s.Encoding)
+	}
+
+	return nil
+}
+
+type ClientDefinition struct {
+	Timeout            int    `json:"timeout"`
+	InsecureSkipVerify *bool  `json:"insecureSkipVerify"`
+	CACert             string `json:"caCert"`
+	ClientCert         string `json:"clientCert"`
+	ClientKey          string `json:"clientKey"`
+	Proxy              string `json:"proxy"`
 }
 
 type WebHookDefinition struct {
-	Method         string            `json:"method"`
-	Path           string            `json:"path"`
-	Delay          int               `json:"delay"`
-	IncludeRequest bool              `json:"includeRequest"`
-	Headers        map[string]string `json:"headers"`
-	Body           map[string]any    `json:"body"`
+	Method         string              `json:"method"`
+	Path           string              `json:"path"`
+	Query          map[string]string   `json:"query"`
+	Delay          int                 `json:"delay"`
+	IncludeRequest bool                `json:"includeRequest"`
+	Headers        map[string]string   `json:"headers"`
+	Body           map[string]any      `json:"body"`
+	Retry          *RetryDefinition    `json:"retry"`
+	Signing        *SigningDefinition  `json:"signing"`
+	Then           []WebHookDefinition `json:"then"`
+	Client         *ClientDefinition   `json:"client"`
+}
+
+type ScheduleDefinition struct {
+	Cron     string `json:"cron"`
+	Interval int    `json:"interval"`
+
+	cron *cronExpression
+}
+
+func (s *ScheduleDefinition) Compile() error {
+	if s.Cron != "" && s.Interval != 0 {
+		return errors.New("schedule can't have both cron and interval")
+	}
+
+	if s.Cron == "" {
+		s.cron = nil
+
+		if s.Interval <= 0 {
+			return errors.New("schedule requires cron expression or positive interval")
+		}
+
+		return nil
+	}
+
+	compiled, err := parseCron(s.Cron)
+	if err != nil {
+		return err
+	}
+
+	s.cron = compiled
+	return nil
+}
+
+func (s *ScheduleDefinition) Next(from time.Time) time.Time {
+	if s.Cron == "" {
+		return from.Add(time.Duration(s.Interval) * time.Millisecond)
+	}
+
+	compiled := s.cron
+	if compiled == nil {
+		parsed, err := parseCron(s.Cron)
+		if err != nil {
+			return time.Time{}
+		}
+
+		compiled = parsed
+	}
+
+	return compiled.next(from)
 }
 
+const StartedState = "Started"
+
+const SequenceMode = "sequence"
+const CycleMode = "cycle"
+const RandomMode = "random"
+
+var responseModes = []string{SequenceMode, CycleMode, RandomMode}
+
 type Flow struct {
-	Request  *RequestDefinition  `json:"request"`
-	Response *ResponseDefinition `json:"response"`
-	WebHook  *WebHookDefinition  `json:"web_hook"`
+	ID            string               `json:"id"`
+	Priority      int                  `json:"priority"`
+	Fallback      bool                 `json:"fallback"`
+	Scenario      string               `json:"scenario"`
+	RequiredState string               `json:"requiredState"`
+	NewState      string               `json:"newState"`
+	Request       *RequestDefinition   `json:"request"`
+	Response      *ResponseDefinition  `json:"response"`
+	Responses     []ResponseDefinition `json:"responses"`
+	ResponseMode  string               `json:"responseMode"`
+	WebHook       *WebHookDefinition   `json:"web_hook"`
+	WebHooks      []WebHookDefinition  `json:"web_hooks"`
+	Schedule      *ScheduleDefinition  `json:"schedule"`
+}
+
+func (f *Flow) AllWebHooks() []*WebHookDefinition {
+	result := make([]*WebHookDefinition, 0, len(f.WebHooks)+1)
+
+	if f.WebHook != nil {
+		result = append(result, f.WebHook)
+	}
+
+	for i := range f.WebHooks {
+		result = append(result, &f.WebHooks[i])
+	}
+
+	return result
 }
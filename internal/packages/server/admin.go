@@ -0,0 +1,320 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const AdminPrefix = "/__admin/"
+
+var ErrNoWebHooks = errors.New("mapping has no webhooks")
+var ErrMissingState = errors.New("missing scenario state")
+
+type admin struct {
+	mapper     mapping.Mapper
+	journal    *journal
+	deliveries *deliveryLog
+	scenarios  *scenarios
+	counters   *responseCounters
+	httpClient HTTPClient
+	appCtx     context.Context
+}
+
+type scenarioRequest struct {
+	State string `json:"state"`
+}
+
+type triggerRequest struct {
+	Body    map[string]any    `json:"body"`
+	Headers map[string]string `json:"headers"`
+	Query   map[string]string `json:"query"`
+}
+
+func (a admin) listMappings(writer http.ResponseWriter, _ *http.Request) {
+	writeJSON(writer, http.StatusOK, a.mapper.GetMappings())
+}
+
+func (a admin) getMapping(writer http.ResponseWriter, request *http.Request) {
+	flow, found := a.mapper.GetMapping(request.PathValue("id"))
+	if !found {
+		writeError(writer, http.StatusNotFound, mapping.ErrMappingNotFound)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, flow)
+}
+
+func (a admin) createMapping(writer http.ResponseWriter, request *http.Request) {
+	var flow mapping.Flow
+
+	err := json.NewDecoder(request.Body).Decode(&flow)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	created, err := a.mapper.AddMapping(flow)
+	if err != nil {
+		writeError(writer, mappingErrorCode(err), err)
+		return
+	}
+
+	writeJSON(writer, http.StatusCreated, created)
+}
+
+func (a admin) updateMapping(writer http.ResponseWriter, request *http.Request) {
+	var flow mapping.Flow
+
+	err := json.NewDecoder(request.Body).Decode(&flow)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	updated, err := a.mapper.UpdateMapping(request.PathValue("id"), flow)
+	if err != nil {
+		writeError(writer, mappingErrorCode(err), err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, updated)
+}
+
+func (a admin) deleteMapping(writer http.ResponseWriter, request *http.Request) {
+	err := a.mapper.DeleteMapping(request.PathValue("id"))
+	if err != nil {
+		writeError(writer, mappingErrorCode(err), err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (a admin) clearMappings(writer http.ResponseWriter, _ *http.Request) {
+	a.mapper.ClearMappings()
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (a admin) listRequests(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, http.StatusOK, a.journal.find(requestsFilter(request)))
+}
+
+func (a admin) countRequests(writer http.ResponseWriter, request *http.Request) {
+	count := len(a.journal.find(requestsFilter(request)))
+
+	writeJSON(writer, http.StatusOK, map[string]int{"count": count})
+}
+
+func (a admin) clearRequests(writer http.ResponseWriter, _ *http.Request) {
+	a.journal.clear()
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func requestsFilter(request *http.Request) journalFilter {
+	query := request.URL.Query()
+
+	return journalFilter{
+		flow:   query.Get("flow"),
+		path:   query.Get("path"),
+		method: query.Get("method"),
+	}
+}
+
+func (a admin) listWebHooks(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, http.StatusOK, a.deliveries.find(webHooksFilter(request)))
+}
+
+func (a admin) countWebHooks(writer http.ResponseWriter, request *http.Request) {
+	count := len(a.deliveries.find(webHooksFilter(request)))
+
+	writeJSON(writer, http.StatusOK, map[string]int{"count": count})
+}
+
+func (a admin) clearWebHooks(writer http.ResponseWriter, _ *http.Request) {
+	a.deliveries.clear()
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func webHooksFilter(request *http.Request) deliveryFilter {
+	query := request.URL.Query()
+
+	return deliveryFilter{
+		flow: query.Get("flow"),
+		url:  query.Get("url"),
+	}
+}
+
+func (a admin) listScenarios(writer http.ResponseWriter, _ *http.Request) {
+	writeJSON(writer, http.StatusOK, a.scenarios.all())
+}
+
+func (a admin) setScenario(writer http.ResponseWriter, request *http.Request) {
+	var state scenarioRequest
+
+	err := json.NewDecoder(request.Body).Decode(&state)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if state.State == "" {
+		writeError(writer, http.StatusBadRequest, ErrMissingState)
+		return
+	}
+
+	a.scenarios.transition(request.PathValue("name"), state.State)
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (a admin) resetScenarios(writer http.ResponseWriter, _ *http.Request) {
+	a.scenarios.reset()
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (a admin) resetSequences(writer http.ResponseWriter, _ *http.Request) {
+	a.counters.reset()
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (a admin) triggerFlow(writer http.ResponseWriter, request *http.Request) {
+	flow, found := a.mapper.GetMapping(request.PathValue("id"))
+	if !found {
+		writeError(writer, http.StatusNotFound, mapping.ErrMappingNotFound)
+		return
+	}
+
+	hooks := flow.AllWebHooks()
+	if len(hooks) == 0 {
+		writeError(writer, http.StatusBadRequest, ErrNoWebHooks)
+		return
+	}
+
+	var trigger triggerRequest
+
+	err := json.NewDecoder(request.Body).Decode(&trigger)
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	header := http.Header{}
+	for k, v := range trigger.Headers {
+		header.Set(k, v)
+	}
+
+	query := url.Values{}
+	for k, v := range trigger.Query {
+		query.Set(k, v)
+	}
+
+	collected := newDeliveryLog(DefaultJournalSize)
+	client := recordingClient{client: a.httpClient, log: collected}
+
+	responder := newDetachedResponder(&flow, trigger.Body, header, query, a.appCtx, client)
+
+	var wg sync.WaitGroup
+
+	for _, hook := range hooks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			responder.triggerWebHook(hook)
+		}()
+	}
+
+	wg.Wait()
+
+	writeJSON(writer, http.StatusOK, collected.find(deliveryFilter{}))
+}
+
+func mappingErrorCode(err error) int {
+	switch {
+	case errors.Is(err, mapping.ErrMappingNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, mapping.ErrMappingExists), errors.Is(err, mapping.ErrMappingReadOnly):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func writeJSON(writer http.ResponseWriter, code int, value any) {
+	marshalled, err := json.Marshal(value)
+	if err != nil {
+		log.Println("unable to marshal admin response", err)
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(code)
+
+	_, err = writer.Write(marshalled)
+	if err != nil {
+		log.Println("unable to send admin response")
+	}
+}
+
+func writeError(writer http.ResponseWriter, code int, err error) {
+	writeJSON(writer, code, map[string]string{"error": err.Error()})
+}
+
+func newAdmin(
+	mapper mapping.Mapper,
+	journal *journal,
+	deliveries *deliveryLog,
+	scenarios *scenarios,
+	counters *responseCounters,
+	client HTTPClient,
+	appCtx context.Context,
+) http.Handler {
+	a := admin{
+		mapper:     mapper,
+		journal:    journal,
+		deliveries: deliveries,
+		scenarios:  scenarios,
+		counters:   counters,
+		httpClient: client,
+		appCtx:     appCtx,
+	}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /__admin/mappings", a.listMappings)
+	mux.HandleFunc("POST /__admin/mappings", a.createMapping)
+	mux.HandleFunc("DELETE /__admin/mappings", a.clearMappings)
+	mux.HandleFunc("GET /__admin/mappings/{id}", a.getMapping)
+	mux.HandleFunc("PUT /__admin/mappings/{id}", a.updateMapping)
+	mux.HandleFunc("DELETE /__admin/mappings/{id}", a.deleteMapping)
+
+	mux.HandleFunc("GET /__admin/requests", a.listRequests)
+	mux.HandleFunc("GET /__admin/requests/count", a.countRequests)
+	mux.HandleFunc("DELETE /__admin/requests", a.clearRequests)
+
+	mux.HandleFunc("GET /__admin/webhooks", a.listWebHooks)
+	mux.HandleFunc("GET /__admin/webhooks/count", a.countWebHooks)
+	mux.HandleFunc("DELETE /__admin/webhooks", a.clearWebHooks)
+
+	mux.HandleFunc("GET /__admin/scenarios", a.listScenarios)
+	mux.HandleFunc("PUT /__admin/scenarios/{name}", a.setScenario)
+	mux.HandleFunc("DELETE /__admin/scenarios", a.resetScenarios)
+
+	mux.HandleFunc("DELETE /__admin/sequences", a.resetSequences)
+
+	mux.HandleFunc("POST /__admin/flows/{id}/trigger", a.triggerFlow)
+
+	return mux
+}
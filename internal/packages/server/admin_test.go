@@ -0,0 +1,285 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/djordjev/webhook-simulator/internal/packages/config"
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestAdminMappings(t *testing.T) {
+	fileSystem := fstest.MapFS{
+		"file.whs": {Data: []byte(`{ "request": { "method": "GET", "path": "/file" }, "response": { "code": 200 } }`)},
+	}
+
+	mapper := mapping.NewMapping(config.Config{}, fileSystem)
+	_ = mapper.Refresh()
+
+	srv := NewServer(config.Config{}, mapper, RequestMatchBuilder, RequestResponseBuilder, context.Background())
+
+	call := func(method string, path string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		response := httptest.NewRecorder()
+
+		srv.ServeHTTP(response, request)
+
+		return response
+	}
+
+	created := call(http.MethodPost, "/__admin/mappings", `{
+		"id": "per-test",
+		"request": { "method": "GET", "path": "/registered" },
+		"response": { "code": 201, "body": { "ok": true } }
+	}`)
+	require.Equal(t, http.StatusCreated, created.Code)
+
+	registered := call(http.MethodGet, "/registered", "")
+	require.Equal(t, http.StatusCreated, registered.Code)
+	require.JSONEq(t, `{"ok": true}`, registered.Body.String())
+
+	duplicate := call(http.MethodPost, "/__admin/mappings", `{ "id": "per-test" }`)
+	require.Equal(t, http.StatusConflict, duplicate.Code)
+
+	invalid := call(http.MethodPost, "/__admin/mappings", `{ "id": `)
+	require.Equal(t, http.StatusBadRequest, invalid.Code)
+
+	withoutResponse := call(http.MethodPost, "/__admin/mappings", `{ "request": { "method": "GET", "path": "/x" } }`)
+	require.Equal(t, http.StatusBadRequest, withoutResponse.Code)
+	require.Equal(t, http.StatusNotFound, call(http.MethodGet, "/x", "").Code)
+
+	list := call(http.MethodGet, "/__admin/mappings", "")
+	require.Equal(t, http.StatusOK, list.Code)
+
+	var flows []mapping.Flow
+	_ = json.Unmarshal(list.Body.Bytes(), &flows)
+	require.Len(t, flows, 2)
+	require.Equal(t, "file.whs", flows[0].ID)
+	require.Equal(t, "per-test", flows[1].ID)
+
+	single := call(http.MethodGet, "/__admin/mappings/per-test", "")
+	require.Equal(t, http.StatusOK, single.Code)
+
+	updated := call(http.MethodPut, "/__admin/mappings/per-test", `{
+		"request": { "method": "GET", "path": "/registered" },
+		"response": { "code": 202 }
+	}`)
+	require.Equal(t, http.StatusOK, updated.Code)
+	require.Equal(t, http.StatusAccepted, call(http.MethodGet, "/registered", "").Code)
+
+	updatedWithoutResponse := call(http.MethodPut, "/__admin/mappings/per-test", `{ "request": { "method": "GET", "path": "/registered" } }`)
+	require.Equal(t, http.StatusBadRequest, updatedWithoutResponse.Code)
+	require.Equal(t, http.StatusAccepted, call(http.MethodGet, "/registered", "").Code)
+
+	readOnly := call(http.MethodDelete, "/__admin/mappings/file.whs", "")
+	require.Equal(t, http.StatusConflict, readOnly.Code)
+
+	deleted := call(http.MethodDelete, "/__admin/mappings/per-test", "")
+	require.Equal(t, http.StatusNoContent, deleted.Code)
+
+	missing := call(http.MethodGet, "/__admin/mappings/per-test", "")
+	require.Equal(t, http.StatusNotFound, missing.Code)
+	require.Equal(t, http.StatusNotFound, call(http.MethodGet, "/registered", "").Code)
+
+	_ = call(http.MethodPost, "/__admin/mappings", `{ "id": "another" }`)
+	cleared := call(http.MethodDelete, "/__admin/mappings", "")
+	require.Equal(t, http.StatusNoContent, cleared.Code)
+	require.Len(t, mapper.GetMappings(), 1)
+}
+
+func TestAdminRequests(t *testing.T) {
+	mapper := newTestMapper([]mapping.Flow{
+		{
+			ID:       "orders",
+			Request:  &mapping.RequestDefinition{Method: http.MethodPost, Path: "/orders"},
+			Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+		},
+	})
+
+	srv := NewServer(config.Config{}, mapper, RequestMatchBuilder, RequestResponseBuilder, context.Background())
+
+	call := func(method string, path string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		response := httptest.NewRecorder()
+
+		srv.ServeHTTP(response, request)
+
+		return response
+	}
+
+	_ = call(http.MethodPost, "/orders", `{"id": 1}`)
+	_ = call(http.MethodPost, "/orders", `{"id": 2}`)
+	_ = call(http.MethodGet, "/unknown", "")
+
+	list := call(http.MethodGet, "/__admin/requests?flow=orders", "")
+	require.Equal(t, http.StatusOK, list.Code)
+
+	var entries []JournalEntry
+	_ = json.Unmarshal(list.Body.Bytes(), &entries)
+	require.Len(t, entries, 2)
+	require.Equal(t, map[string]any{"id": float64(1)}, entries[0].Body)
+	require.Equal(t, map[string]any{"id": float64(2)}, entries[1].Body)
+
+	count := call(http.MethodGet, "/__admin/requests/count?path=/unknown", "")
+	require.Equal(t, http.StatusOK, count.Code)
+	require.JSONEq(t, `{"count": 1}`, count.Body.String())
+
+	cleared := call(http.MethodDelete, "/__admin/requests", "")
+	require.Equal(t, http.StatusNoContent, cleared.Code)
+	require.JSONEq(t, `{"count": 0}`, call(http.MethodGet, "/__admin/requests/count", "").Body.String())
+}
+
+func TestAdminWebHooks(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusAccepted)
+		_, _ = writer.Write([]byte("received"))
+	}))
+	defer receiver.Close()
+
+	mapper := newTestMapper([]mapping.Flow{
+		{
+			ID:       "orders",
+			Request:  &mapping.RequestDefinition{Method: http.MethodPost, Path: "/orders"},
+			Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+			WebHook: &mapping.WebHookDefinition{
+				Method: http.MethodPost,
+				Path:   receiver.URL + "/events",
+				Body:   map[string]any{"id": "${{body.id}}"},
+			},
+		},
+	})
+
+	srv := NewServer(config.Config{}, mapper, RequestMatchBuilder, RequestResponseBuilder, context.Background())
+
+	call := func(method string, path string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		response := httptest.NewRecorder()
+
+		srv.ServeHTTP(response, request)
+
+		return response
+	}
+
+	_ = call(http.MethodPost, "/orders", `{"id": "ord_1"}`)
+
+	require.Eventually(t, func() bool {
+		return call(http.MethodGet, "/__admin/webhooks/count?flow=orders", "").Body.String() == `{"count":1}`
+	}, time.Second, 10*time.Millisecond)
+
+	list := call(http.MethodGet, "/__admin/webhooks?url=/events", "")
+
+	var deliveries []Delivery
+	_ = json.Unmarshal(list.Body.Bytes(), &deliveries)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, receiver.URL+"/events", deliveries[0].URL)
+	require.JSONEq(t, `{"id": "ord_1"}`, deliveries[0].Payload)
+	require.Equal(t, http.StatusAccepted, deliveries[0].Status)
+	require.Equal(t, "received", deliveries[0].Response)
+
+	cleared := call(http.MethodDelete, "/__admin/webhooks", "")
+	require.Equal(t, http.StatusNoContent, cleared.Code)
+	require.JSONEq(t, `{"count": 0}`, call(http.MethodGet, "/__admin/webhooks/count", "").Body.String())
+}
+
+func TestAdminTriggerFlow(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusAccepted)
+		_, _ = writer.Write([]byte("received"))
+	}))
+	defer receiver.Close()
+
+	mapper := newTestMapper([]mapping.Flow{
+		{
+			ID:       "payments",
+			Request:  &mapping.RequestDefinition{Method: http.MethodPost, Path: "/payments"},
+			Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+			WebHook: &mapping.WebHookDefinition{
+				Method:  http.MethodPost,
+				Path:    receiver.URL + "/events",
+				Query:   map[string]string{"source": "${{query.source}}"},
+				Headers: map[string]string{"X-Tenant": "${{header.X-Tenant}}"},
+				Body:    map[string]any{"type": "payment.succeeded", "id": "${{body.id}}"},
+			},
+		},
+		respondingFlow("no-webhooks", 0, &mapping.RequestDefinition{Method: http.MethodGet, Path: "/"}),
+	})
+
+	srv := NewServer(config.Config{}, mapper, RequestMatchBuilder, RequestResponseBuilder, context.Background())
+
+	call := func(method string, path string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		response := httptest.NewRecorder()
+
+		srv.ServeHTTP(response, request)
+
+		return response
+	}
+
+	triggered := call(
+		http.MethodPost,
+		"/__admin/flows/payments/trigger",
+		`{"body": {"id": "pay_1"}, "headers": {"X-Tenant": "acme"}, "query": {"source": "qa"}}`,
+	)
+	require.Equal(t, http.StatusOK, triggered.Code)
+
+	var deliveries []Delivery
+	_ = json.Unmarshal(triggered.Body.Bytes(), &deliveries)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, "payments", deliveries[0].Flow)
+	require.Equal(t, receiver.URL+"/events?source=qa", deliveries[0].URL)
+	require.Equal(t, "acme", deliveries[0].Headers.Get("X-Tenant"))
+	require.JSONEq(t, `{"type": "payment.succeeded", "id": "pay_1"}`, deliveries[0].Payload)
+	require.Equal(t, http.StatusAccepted, deliveries[0].Status)
+
+	require.JSONEq(t, `{"count": 1}`, call(http.MethodGet, "/__admin/webhooks/count?flow=payments", "").Body.String())
+	require.JSONEq(t, `{"count": 0}`, call(http.MethodGet, "/__admin/requests/count", "").Body.String())
+
+	withoutBody := call(http.MethodPost, "/__admin/flows/payments/trigger", "")
+	require.Equal(t, http.StatusOK, withoutBody.Code)
+
+	missing := call(http.MethodPost, "/__admin/flows/missing/trigger", "")
+	require.Equal(t, http.StatusNotFound, missing.Code)
+
+	noWebHooks := call(http.MethodPost, "/__admin/flows/no-webhooks/trigger", "")
+	require.Equal(t, http.StatusBadRequest, noWebHooks.Code)
+	require.JSONEq(t, `{"error": "mapping has no webhooks"}`, noWebHooks.Body.String())
+
+	invalid := call(http.MethodPost, "/__admin/flows/payments/trigger", `{"body": [`)
+	require.Equal(t, http.StatusBadRequest, invalid.Code)
+}
+
+func TestAdminScenarios(t *testing.T) {
+	srv := NewServer(config.Config{}, newTestMapper([]mapping.Flow{}), RequestMatchBuilder, RequestResponseBuilder, context.Background())
+
+	call := func(method string, path string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		response := httptest.NewRecorder()
+
+		srv.ServeHTTP(response, request)
+
+		return response
+	}
+
+	require.JSONEq(t, `{}`, call(http.MethodGet, "/__admin/scenarios", "").Body.String())
+
+	set := call(http.MethodPut, "/__admin/scenarios/approval", `{"state": "approved"}`)
+	require.Equal(t, http.StatusNoContent, set.Code)
+	require.JSONEq(t, `{"approval": "approved"}`, call(http.MethodGet, "/__admin/scenarios", "").Body.String())
+
+	missing := call(http.MethodPut, "/__admin/scenarios/approval", `{}`)
+	require.Equal(t, http.StatusBadRequest, missing.Code)
+	require.JSONEq(t, `{"error": "missing scenario state"}`, missing.Body.String())
+
+	invalid := call(http.MethodPut, "/__admin/scenarios/approval", `{"state": `)
+	require.Equal(t, http.StatusBadRequest, invalid.Code)
+
+	reset := call(http.MethodDelete, "/__admin/scenarios", "")
+	require.Equal(t, http.StatusNoContent, reset.Code)
+	require.JSONEq(t, `{}`, call(http.MethodGet, "/__admin/scenarios", "").Body.String())
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+const RawBody = "$raw"
+
+const maxMultipartMemory = 32 << 20
+
+type textualBodyKey struct{}
+
+func parseBody(request *http.Request) (map[string]any, bool, error) {
+	payload := make(map[string]any)
+
+	if request.Body == nil {
+		return payload, false, nil
+	}
+
+	data, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return payload, false, nil
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		{
+			values, err := url.ParseQuery(string(data))
+			if err == nil {
+				return formValues(values), true, nil
+			}
+		}
+
+	case "multipart/form-data":
+		{
+			form, err := multipart.NewReader(bytes.NewReader(data), params["boundary"]).ReadForm(maxMultipartMemory)
+			if err == nil {
+				defer func() {
+					_ = form.RemoveAll()
+				}()
+
+				return multipartValues(form), true, nil
+			}
+		}
+
+	default:
+		{
+			if isXMLMediaType(mediaType) {
+				parsed, err := parseXML(data)
+				if err == nil {
+					return parsed, true, nil
+				}
+
+				break
+			}
+
+			err = json.Unmarshal(data, &payload)
+			if err == nil {
+				return payload, false, nil
+			}
+		}
+	}
+
+	return map[string]any{RawBody: string(data)}, false, nil
+}
+
+func formValues(values map[string][]string) map[string]any {
+	result := make(map[string]any)
+
+	for k, v := range values {
+		if len(v) == 1 {
+			result[k] = v[0]
+			continue
+		}
+
+		elements := make([]any, 0, len(v))
+		for _, elem := range v {
+			elements = append(elements, elem)
+		}
+
+		result[k] = elements
+	}
+
+	return result
+}
+
+func multipartValues(form *multipart.Form) map[string]any {
+	result := formValues(form.Value)
+
+	for k, files := range form.File {
+		elements := make([]any, 0, len(files))
+
+		for _, file := range files {
+			elements = append(elements, map[string]any{
+				"name":        file.Filename,
+				"size":        float64(file.Size),
+				"contentType": file.Header.Get("Content-Type"),
+			})
+		}
+
+		if len(elements) == 1 {
+			result[k] = elements[0]
+		} else {
+			result[k] = elements
+		}
+	}
+
+	return result
+}
+
+func withTextualBody(ctx context.Context, textual bool) context.Context {
+	return context.WithValue(ctx, textualBodyKey{}, textual)
+}
+
+func isTextualBody(ctx context.Context) bool {
+	textual, _ := ctx.Value(textualBodyKey{}).(bool)
+	return textual
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestParseBody(t *testing.T) {
+	testCases := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		payload     map[string]any
+		textual     bool
+	}{
+		{
+			name:    "parses JSON object",
+			method:  http.MethodPost,
+			body:    `{"user": {"name": "Jon"}}`,
+			payload: map[string]any{"user": map[string]any{"name": "Jon"}},
+		},
+		{
+			name:    "treats empty body as empty payload",
+			method:  http.MethodGet,
+			body:    "",
+			payload: map[string]any{},
+		},
+		{
+			name:    "treats whitespace body as empty payload",
+			method:  http.MethodPost,
+			body:    "  \n ",
+			payload: map[string]any{},
+		},
+		{
+			name:    "passes non JSON body as raw content",
+			method:  http.MethodPost,
+			body:    "plain text",
+			payload: map[string]any{RawBody: "plain text"},
+		},
+		{
+			name:    "passes JSON that is not an object as raw content",
+			method:  http.MethodPost,
+			body:    `[1, 2]`,
+			payload: map[string]any{RawBody: "[1, 2]"},
+		},
+		{
+			name:        "parses form urlencoded body",
+			method:      http.MethodPost,
+			contentType: "application/x-www-form-urlencoded",
+			body:        "From=%2B123&Body=hello&Tag=a&Tag=b",
+			payload: map[string]any{
+				"From": "+123",
+				"Body": "hello",
+				"Tag":  []any{"a", "b"},
+			},
+			textual: true,
+		},
+		{
+			name:        "parses multipart form with files",
+			method:      http.MethodPost,
+			contentType: "multipart/form-data; boundary=XXX",
+			body: "--XXX\r\n" +
+				"Content-Disposition: form-data; name=\"event\"\r\n\r\n" +
+				"created\r\n" +
+				"--XXX\r\n" +
+				"Content-Disposition: form-data; name=\"document\"; filename=\"doc.txt\"\r\n" +
+				"Content-Type: text/plain\r\n\r\n" +
+				"hello\r\n" +
+				"--XXX--\r\n",
+			payload: map[string]any{
+				"event": "created",
+				"document": map[string]any{
+					"name":        "doc.txt",
+					"size":        float64(5),
+					"contentType": "text/plain",
+				},
+			},
+			textual: true,
+		},
+		{
+			name:        "parses XML body",
+			method:      http.MethodPost,
+			contentType: "text/xml; charset=utf-8",
+			body:        `<Payment id="p1"><Amount>150</Amount></Payment>`,
+			payload: map[string]any{
+				"Payment": map[string]any{"@id": "p1", "Amount": "150"},
+			},
+			textual: true,
+		},
+		{
+			name:        "passes malformed XML body as raw content",
+			method:      http.MethodPost,
+			contentType: "application/soap+xml",
+			body:        "<Payment>",
+			payload:     map[string]any{RawBody: "<Payment>"},
+		},
+		{
+			name:        "passes malformed multipart body as raw content",
+			method:      http.MethodPost,
+			contentType: "multipart/form-data; boundary=XXX",
+			body:        "not multipart",
+			payload:     map[string]any{RawBody: "not multipart"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			request, _ := http.NewRequest(test.method, "/path", bytes.NewBufferString(test.body))
+			if test.contentType != "" {
+				request.Header.Set("Content-Type", test.contentType)
+			}
+
+			payload, textual, err := parseBody(request)
+
+			require.NoError(t, err)
+			require.Equal(t, test.payload, payload)
+			require.Equal(t, test.textual, textual)
+		})
+	}
+
+	t.Run("treats missing body as empty payload", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/path", nil)
+
+		payload, textual, err := parseBody(request)
+
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{}, payload)
+		require.False(t, textual)
+	})
+}
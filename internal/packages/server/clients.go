@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/djordjev/webhook-simulator/internal/packages/config"
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+type clientKey struct{}
+
+type webHookClient struct {
+	defaults config.WebHookClient
+	lock     sync.Mutex
+	clients  map[config.WebHookClient]*http.Client
+}
+
+func (c *webHookClient) Do(req *http.Request) (*http.Response, error) {
+	settings := c.defaults
+
+	if definition, ok := req.Context().Value(clientKey{}).(*mapping.ClientDefinition); ok && definition != nil {
+		settings = mergeClientSettings(settings, *definition)
+	}
+
+	client, err := c.client(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Do(req)
+}
+
+func (c *webHookClient) client(settings config.WebHookClient) (*http.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if client, found := c.clients[settings]; found {
+		return client, nil
+	}
+
+	client, err := newHTTPClient(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	c.clients[settings] = client
+
+	return client, nil
+}
+
+func mergeClientSettings(defaults config.WebHookClient, overrides mapping.ClientDefinition) config.WebHookClient {
+	result := defaults
+
+	if overrides.Timeout > 0 {
+		result.Timeout = overrides.Timeout
+	}
+
+	if overrides.InsecureSkipVerify != nil {
+		result.InsecureSkipVerify = *overrides.InsecureSkipVerify
+	}
+
+	if overrides.CACert != "" {
+		result.CACert = overrides.CACert
+	}
+
+	if overrides.ClientCert != "" || overrides.ClientKey != "" {
+		result.ClientCert = overrides.ClientCert
+		result.ClientKey = overrides.ClientKey
+	}
+
+	if overrides.Proxy != "" {
+		result.Proxy = overrides.Proxy
+	}
+
+	return result
+}
+
+func newHTTPClient(settings config.WebHookClient) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: settings.InsecureSkipVerify}
+
+	if settings.CACert != "" {
+		pem, err := os.ReadFile(settings.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA bundle: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in CA bundle " + settings.CACert)
+		}
+
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if settings.ClientCert != "" || settings.ClientKey != "" {
+		certificate, err := tls.LoadX509KeyPair(settings.ClientCert, settings.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+
+		transport.TLSClientConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if settings.Proxy != "" {
+		proxy, err := url.Parse(settings.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(settings.Timeout) * time.Millisecond,
+	}
+
+	return client, nil
+}
+
+func newWebHookClient(cfg config.WebHookClient) *webHookClient {
+	return &webHookClient{defaults: cfg, clients: make(map[config.WebHookClient]*http.Client)}
+}
+
+func withClient(ctx context.Context, definition *mapping.ClientDefinition) context.Context {
+	return context.WithValue(ctx, clientKey{}, definition)
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"encoding/pem"
+	"github.com/djordjev/webhook-simulator/internal/packages/config"
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWebHookClient(t *testing.T) {
+	receiver := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	caCert := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: receiver.Certificate().Raw}), 0o600)
+	require.NoError(t, err)
+
+	insecure, secure := true, false
+
+	testCases := []struct {
+		name     string
+		defaults config.WebHookClient
+		client   *mapping.ClientDefinition
+		path     string
+		success  bool
+	}{
+		{
+			name:    "rejects self signed certificate by default",
+			path:    "/",
+			success: false,
+		},
+		{
+			name:    "skips certificate verification for webhook",
+			client:  &mapping.ClientDefinition{InsecureSkipVerify: &insecure},
+			path:    "/",
+			success: true,
+		},
+		{
+			name:     "skips certificate verification globally",
+			defaults: config.WebHookClient{InsecureSkipVerify: true},
+			path:     "/",
+			success:  true,
+		},
+		{
+			name:     "verifies certificate for webhook when skipped globally",
+			defaults: config.WebHookClient{InsecureSkipVerify: true},
+			client:   &mapping.ClientDefinition{InsecureSkipVerify: &secure},
+			path:     "/",
+			success:  false,
+		},
+		{
+			name:     "keeps global certificate verification setting when not overridden",
+			defaults: config.WebHookClient{InsecureSkipVerify: true},
+			client:   &mapping.ClientDefinition{Timeout: 5000},
+			path:     "/",
+			success:  true,
+		},
+		{
+			name:    "trusts custom CA bundle",
+			client:  &mapping.ClientDefinition{CACert: caCert},
+			path:    "/",
+			success: true,
+		},
+		{
+			name:    "fails with missing CA bundle",
+			client:  &mapping.ClientDefinition{CACert: filepath.Join(t.TempDir(), "missing.pem")},
+			path:    "/",
+			success: false,
+		},
+		{
+			name:    "fails with missing client certificate",
+			client:  &mapping.ClientDefinition{InsecureSkipVerify: &insecure, ClientCert: "missing.pem", ClientKey: "missing.key"},
+			path:    "/",
+			success: false,
+		},
+		{
+			name:     "webhook timeout overrides global timeout",
+			defaults: config.WebHookClient{InsecureSkipVerify: true, Timeout: 5000},
+			client:   &mapping.ClientDefinition{Timeout: 50},
+			path:     "/slow",
+			success:  false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			client := newWebHookClient(test.defaults)
+
+			req, _ := http.NewRequestWithContext(withClient(context.Background(), test.client), http.MethodPost, receiver.URL+test.path, nil)
+
+			res, err := client.Do(req)
+
+			if !test.success {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, http.StatusNoContent, res.StatusCode)
+		})
+	}
+}
+
+func TestWebHookClientProxy(t *testing.T) {
+	proxied := make(chan string, 1)
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer proxy.Close()
+
+	client := newWebHookClient(config.WebHookClient{Proxy: proxy.URL})
+
+	req, _ := http.NewRequest(http.MethodPost, "http://receiver.internal/events", nil)
+
+	res, err := client.Do(req)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, res.StatusCode)
+	require.Equal(t, "http://receiver.internal/events", <-proxied)
+}
+
+func TestWebHookClientCache(t *testing.T) {
+	client := newWebHookClient(config.WebHookClient{Timeout: 1000})
+	insecure := true
+
+	first, err := client.client(mergeClientSettings(client.defaults, mapping.ClientDefinition{InsecureSkipVerify: &insecure}))
+	require.NoError(t, err)
+
+	second, err := client.client(mergeClientSettings(client.defaults, mapping.ClientDefinition{InsecureSkipVerify: &insecure}))
+	require.NoError(t, err)
+
+	other, err := client.client(client.defaults)
+	require.NoError(t, err)
+
+	require.Same(t, first, second)
+	require.NotSame(t, first, other)
+	require.Equal(t, time.Second, first.Timeout)
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type flowKey struct{}
+
+type Delivery struct {
+	Flow      string      `json:"flow"`
+	URL       string      `json:"url"`
+	Method    string      `json:"method"`
+	Headers   http.Header `json:"headers"`
+	Payload   string      `json:"payload"`
+	Status    int         `json:"status"`
+	Response  string      `json:"response"`
+	Latency   int64       `json:"latency"`
+	Error     string      `json:"error"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+type deliveryFilter struct {
+	flow string
+	url  string
+}
+
+func (f deliveryFilter) matches(delivery Delivery) bool {
+	if f.flow != "" && f.flow != delivery.Flow {
+		return false
+	}
+
+	if f.url != "" && !strings.Contains(delivery.URL, f.url) {
+		return false
+	}
+
+	return true
+}
+
+type deliveryLog struct {
+	lock       sync.Mutex
+	size       int
+	deliveries []Delivery
+}
+
+func (d *deliveryLog) record(delivery Delivery) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.deliveries = append(d.deliveries, delivery)
+
+	if len(d.deliveries) > d.size {
+		d.deliveries = slices.Clone(d.deliveries[len(d.deliveries)-d.size:])
+	}
+}
+
+func (d *deliveryLog) find(filter deliveryFilter) []Delivery {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	result := make([]Delivery, 0)
+
+	for _, delivery := range d.deliveries {
+		if filter.matches(delivery) {
+			result = append(result, delivery)
+		}
+	}
+
+	return result
+}
+
+func (d *deliveryLog) clear() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.deliveries = make([]Delivery, 0)
+}
+
+func newDeliveryLog(size int) *deliveryLog {
+	if size <= 0 {
+		size = DefaultJournalSize
+	}
+
+	return &deliveryLog{size: size, deliveries: make([]Delivery, 0)}
+}
+
+type recordingClient struct {
+	client HTTPClient
+	log    *deliveryLog
+}
+
+func (c recordingClient) Do(req *http.Request) (*http.Response, error) {
+	delivery := Delivery{
+		URL:       req.URL.String(),
+		Method:    req.Method,
+		Headers:   req.Header.Clone(),
+		Payload:   requestPayload(req),
+		Timestamp: now(),
+	}
+
+	if flow, ok := req.Context().Value(flowKey{}).(string); ok {
+		delivery.Flow = flow
+	}
+
+	res, err := c.client.Do(req)
+
+	delivery.Latency = now().Sub(delivery.Timestamp).Milliseconds()
+
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	if res != nil {
+		delivery.Status = res.StatusCode
+
+		if res.Body != nil {
+			body, readErr := io.ReadAll(res.Body)
+			_ = res.Body.Close()
+
+			if readErr != nil {
+				delivery.Error = readErr.Error()
+			}
+
+			delivery.Response = string(body)
+			res.Body = io.NopCloser(bytes.NewReader(body))
+		}
+	}
+
+	c.log.record(delivery)
+
+	return res, err
+}
+
+func requestPayload(req *http.Request) string {
+	if req.GetBody == nil {
+		return ""
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return ""
+	}
+
+	return string(payload)
+}
+
+func withFlow(ctx context.Context, flow string) context.Context {
+	return context.WithValue(ctx, flowKey{}, flow)
+}
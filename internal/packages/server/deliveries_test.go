@@ -0,0 +1,66 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestRecordingClient(t *testing.T) {
+	t.Run("records delivered webhook", func(t *testing.T) {
+		mocked := mockHttpClient{}
+		deliveries := newDeliveryLog(10)
+		client := recordingClient{client: &mocked, log: deliveries}
+
+		response := http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(bytes.NewBufferString(`{"id": "evt_1"}`))}
+		mocked.On("Do", mock.Anything).Return(&response, nil)
+
+		req, _ := http.NewRequestWithContext(
+			withFlow(context.Background(), "orders.whs"),
+			http.MethodPost,
+			"http://receiver/events",
+			bytes.NewBufferString(`{"type": "created"}`),
+		)
+		req.Header.Set("X-Api-Key", "abc")
+
+		res, err := client.Do(req)
+		require.NoError(t, err)
+
+		body, _ := io.ReadAll(res.Body)
+		require.Equal(t, `{"id": "evt_1"}`, string(body))
+
+		recorded := deliveries.find(deliveryFilter{})
+		require.Len(t, recorded, 1)
+		require.Equal(t, "orders.whs", recorded[0].Flow)
+		require.Equal(t, "http://receiver/events", recorded[0].URL)
+		require.Equal(t, http.MethodPost, recorded[0].Method)
+		require.Equal(t, "abc", recorded[0].Headers.Get("X-Api-Key"))
+		require.Equal(t, `{"type": "created"}`, recorded[0].Payload)
+		require.Equal(t, http.StatusAccepted, recorded[0].Status)
+		require.Equal(t, `{"id": "evt_1"}`, recorded[0].Response)
+		require.Empty(t, recorded[0].Error)
+	})
+
+	t.Run("records failed webhook", func(t *testing.T) {
+		mocked := mockHttpClient{}
+		deliveries := newDeliveryLog(10)
+		client := recordingClient{client: &mocked, log: deliveries}
+
+		mocked.On("Do", mock.Anything).Return((*http.Response)(nil), errors.New("connection refused"))
+
+		req, _ := http.NewRequest(http.MethodPost, "http://receiver/events", nil)
+
+		_, err := client.Do(req)
+		require.Error(t, err)
+
+		recorded := deliveries.find(deliveryFilter{url: "receiver"})
+		require.Len(t, recorded, 1)
+		require.Equal(t, "connection refused", recorded[0].Error)
+		require.Equal(t, 0, recorded[0].Status)
+	})
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const maxNearMisses = 3
+
+type nearMiss struct {
+	ID         string   `json:"id"`
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Mismatches []string `json:"mismatches"`
+}
+
+type diagnostic struct {
+	Error      string         `json:"error"`
+	Request    map[string]any `json:"request"`
+	NearMisses []nearMiss     `json:"nearMisses"`
+}
+
+func findNearMisses(flows []mapping.Flow, matchers []Matcher) []nearMiss {
+	result := make([]nearMiss, 0)
+
+	for index, flow := range flows {
+		miss := nearMiss{ID: flow.ID, Mismatches: matchers[index].Mismatches()}
+
+		if flow.Request != nil {
+			miss.Method = flow.Request.Method
+			miss.Path = flow.Request.Path
+			if flow.Request.PathPattern != "" {
+				miss.Path = flow.Request.PathPattern
+			}
+		}
+
+		result = append(result, miss)
+	}
+
+	slices.SortStableFunc(result, func(a nearMiss, b nearMiss) int {
+		return len(a.Mismatches) - len(b.Mismatches)
+	})
+
+	if len(result) > maxNearMisses {
+		result = result[:maxNearMisses]
+	}
+
+	return result
+}
+
+func newDiagnostic(request *http.Request, nearMisses []nearMiss) diagnostic {
+	return diagnostic{
+		Error: "no mapping matched the request",
+		Request: map[string]any{
+			"method": request.Method,
+			"path":   request.URL.Path,
+			"query":  request.URL.RawQuery,
+		},
+		NearMisses: nearMisses,
+	}
+}
+
+func (d diagnostic) String() string {
+	misses := make([]string, 0, len(d.NearMisses))
+
+	for _, miss := range d.NearMisses {
+		misses = append(misses, fmt.Sprintf("%s [%s]", miss.ID, strings.Join(miss.Mismatches, ", ")))
+	}
+
+	return fmt.Sprintf(
+		"no mapping matched %s %s. closest mappings: %s",
+		d.Request["method"],
+		d.Request["path"],
+		strings.Join(misses, "; "),
+	)
+}
+
+func (d diagnostic) Marshal() []byte {
+	marshalled, err := json.Marshal(d)
+	if err != nil {
+		return []byte("")
+	}
+
+	return marshalled
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"github.com/google/uuid"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const DefaultJournalSize = 1000
+
+var now = time.Now
+
+type JournalEntry struct {
+	ID        string         `json:"id"`
+	Method    string         `json:"method"`
+	Path      string         `json:"path"`
+	Query     string         `json:"query"`
+	Headers   http.Header    `json:"headers"`
+	Body      map[string]any `json:"body"`
+	Flow      string         `json:"flow"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+type journalFilter struct {
+	flow   string
+	path   string
+	method string
+}
+
+func (f journalFilter) matches(entry JournalEntry) bool {
+	if f.flow != "" && f.flow != entry.Flow {
+		return false
+	}
+
+	if f.method != "" && f.method != entry.Method {
+		return false
+	}
+
+	if f.path != "" {
+		if _, ok := matchPath(f.path, entry.Path); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+type journal struct {
+	lock    sync.Mutex
+	size    int
+	entries []JournalEntry
+}
+
+func (j *journal) record(request *http.Request, body map[string]any, flow string) {
+	entry := JournalEntry{
+		ID:        uuid.NewString(),
+		Method:    request.Method,
+		Path:      request.URL.Path,
+		Query:     request.URL.RawQuery,
+		Headers:   request.Header.Clone(),
+		Body:      deepCopy(body),
+		Flow:      flow,
+		Timestamp: now(),
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.entries = append(j.entries, entry)
+
+	if len(j.entries) > j.size {
+		j.entries = slices.Clone(j.entries[len(j.entries)-j.size:])
+	}
+}
+
+func (j *journal) find(filter journalFilter) []JournalEntry {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	result := make([]JournalEntry, 0)
+
+	for _, entry := range j.entries {
+		if filter.matches(entry) {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+func (j *journal) clear() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.entries = make([]JournalEntry, 0)
+}
+
+func deepCopy(value map[string]any) map[string]any {
+	if value == nil {
+		return nil
+	}
+
+	result := make(map[string]any, len(value))
+
+	for k, v := range value {
+		result[k] = deepCopyValue(v)
+	}
+
+	return result
+}
+
+func deepCopyValue(value any) any {
+	switch t := value.(type) {
+	case map[string]any:
+		return deepCopy(t)
+
+	case []any:
+		{
+			result := make([]any, 0, len(t))
+			for _, elem := range t {
+				result = append(result, deepCopyValue(elem))
+			}
+
+			return result
+		}
+
+	default:
+		return t
+	}
+}
+
+func newJournal(size int) *journal {
+	if size <= 0 {
+		size = DefaultJournalSize
+	}
+
+	return &journal{size: size, entries: make([]JournalEntry, 0)}
+}
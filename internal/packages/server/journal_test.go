@@ -0,0 +1,81 @@
+package server
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2024, 10, 27, 20, 34, 58, 0, time.UTC)
+	}
+	defer func() {
+		now = time.Now
+	}()
+
+	newRequest := func(method string, url string) *http.Request {
+		request, _ := http.NewRequest(method, url, nil)
+		request.Header.Set("X-Api-Key", "abc")
+		return request
+	}
+
+	t.Run("records requests", func(t *testing.T) {
+		requests := newJournal(10)
+		body := map[string]any{"user": map[string]any{"name": "Jon"}}
+
+		requests.record(newRequest(http.MethodPost, "/users?page=1"), body, "users.whs")
+		body["user"].(map[string]any)["name"] = "Changed"
+
+		entries := requests.find(journalFilter{})
+
+		require.Len(t, entries, 1)
+		require.NotEmpty(t, entries[0].ID)
+		require.Equal(t, http.MethodPost, entries[0].Method)
+		require.Equal(t, "/users", entries[0].Path)
+		require.Equal(t, "page=1", entries[0].Query)
+		require.Equal(t, "abc", entries[0].Headers.Get("X-Api-Key"))
+		require.Equal(t, map[string]any{"user": map[string]any{"name": "Jon"}}, entries[0].Body)
+		require.Equal(t, "users.whs", entries[0].Flow)
+		require.Equal(t, now(), entries[0].Timestamp)
+	})
+
+	t.Run("keeps only latest requests", func(t *testing.T) {
+		requests := newJournal(2)
+
+		requests.record(newRequest(http.MethodGet, "/first"), map[string]any{}, "")
+		requests.record(newRequest(http.MethodGet, "/second"), map[string]any{}, "")
+		requests.record(newRequest(http.MethodGet, "/third"), map[string]any{}, "")
+
+		entries := requests.find(journalFilter{})
+
+		require.Len(t, entries, 2)
+		require.Equal(t, "/second", entries[0].Path)
+		require.Equal(t, "/third", entries[1].Path)
+	})
+
+	t.Run("filters requests", func(t *testing.T) {
+		requests := newJournal(10)
+
+		requests.record(newRequest(http.MethodGet, "/users/1"), map[string]any{}, "users.whs")
+		requests.record(newRequest(http.MethodPost, "/users/2"), map[string]any{}, "users.whs")
+		requests.record(newRequest(http.MethodGet, "/orders/1"), map[string]any{}, "orders.whs")
+		requests.record(newRequest(http.MethodGet, "/unknown"), map[string]any{}, "")
+
+		require.Len(t, requests.find(journalFilter{flow: "users.whs"}), 2)
+		require.Len(t, requests.find(journalFilter{path: "/users/{id}"}), 2)
+		require.Len(t, requests.find(journalFilter{path: "/orders/1"}), 1)
+		require.Len(t, requests.find(journalFilter{method: http.MethodGet}), 3)
+		require.Len(t, requests.find(journalFilter{flow: "users.whs", method: http.MethodGet}), 1)
+	})
+
+	t.Run("clears requests", func(t *testing.T) {
+		requests := newJournal(10)
+		requests.record(newRequest(http.MethodGet, "/users/1"), map[string]any{}, "")
+
+		requests.clear()
+
+		require.Empty(t, requests.find(journalFilter{}))
+	})
+}
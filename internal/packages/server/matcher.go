@@ -1,154 +1,356 @@
 package server
 
 import (
+	"fmt"
 	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
 	"log"
 	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
 )
 
 type Matcher interface {
 	Match()
 	IsMatch() bool
+	Mismatches() []string
 }
 
 type RequestMatcher struct {
-	request *http.Request
-	flow    *mapping.Flow
-	body    map[string]any
-	isMatch bool
+	request    *http.Request
+	flow       *mapping.Flow
+	body       map[string]any
+	isMatch    bool
+	mismatches []string
 }
 
 func (m *RequestMatcher) Match() {
+	m.isMatch = false
+	m.mismatches = make([]string, 0)
+
 	defer func() {
 		if r := recover(); r != nil {
 			log.Println("Recovered in f", r)
+			m.isMatch = false
+			m.mismatches = append(m.mismatches, "invalid mapping")
 		}
 	}()
 
 	flowRequest := m.flow.Request
 
 	// Match method
-	if m.request.Method != flowRequest.Method {
-		return
+	if flowRequest.Method != "" && m.request.Method != flowRequest.Method {
+		m.mismatch("method: expected %s but got %s", flowRequest.Method, m.request.Method)
 	}
 
 	// Match path
-	if m.request.URL.Path != flowRequest.Path {
-		return
+	if _, ok := pathParams(flowRequest, m.request.URL.Path); !ok {
+		expected := flowRequest.Path
+		if flowRequest.PathPattern != "" {
+			expected = flowRequest.PathPattern
+		}
+
+		m.mismatch("path: expected %s but got %s", expected, m.request.URL.Path)
+	}
+
+	// Match query
+	for _, key := range m.queryMismatches() {
+		m.mismatch("query: %s", key)
 	}
 
 	// Match body
-	if !isMatching(m.flow.Request.Body, m.body) {
-		return
+	values := valueMatcher{textual: isTextualBody(m.request.Context()), request: flowRequest}
+	for _, key := range values.bodyMismatches(flowRequest.Body, m.body) {
+		m.mismatch("body: %s", key)
 	}
 
 	// Match headers
-	if !m.headersMatching() {
-		return
+	for _, key := range m.headersMismatches() {
+		m.mismatch("header: %s", key)
+	}
+
+	// Match scenario state
+	if m.flow.Scenario != "" && m.flow.RequiredState != "" {
+		state := scenarioState(m.request.Context(), m.flow.Scenario)
+		if state != m.flow.RequiredState {
+			m.mismatch("scenario %s: expected state %s but got %s", m.flow.Scenario, m.flow.RequiredState, state)
+		}
 	}
 
-	m.isMatch = true
+	m.isMatch = len(m.mismatches) == 0
 
 	return
 }
 
-func (m *RequestMatcher) headersMatching() bool {
-	headers := m.flow.Request.Headers
+func (m *RequestMatcher) mismatch(format string, args ...any) {
+	m.mismatches = append(m.mismatches, fmt.Sprintf(format, args...))
+}
 
-	if headers == nil {
-		return true
+func (m *RequestMatcher) headersMismatches() []string {
+	result := make([]string, 0)
+	matcher := valueMatcher{textual: true, request: m.flow.Request}
+
+	for k, v := range m.flow.Request.Headers {
+		values := headerValues(m.request.Header, k)
+
+		expected, ok := v.([]any)
+		if !ok {
+			expected = []any{v}
+		}
+
+		for _, elem := range expected {
+			if !matcher.headerMatching(elem, values) {
+				result = append(result, k)
+				break
+			}
+		}
 	}
 
-	for k, v := range headers {
-		header := m.request.Header.Get(k)
-		if header != v {
-			return false
+	slices.Sort(result)
+
+	return result
+}
+
+func headerValues(header http.Header, key string) []string {
+	result := make([]string, 0)
+
+	for _, value := range header.Values(key) {
+		result = append(result, value)
+
+		if !strings.Contains(value, ",") {
+			continue
+		}
+
+		for _, part := range strings.Split(value, ",") {
+			result = append(result, strings.TrimSpace(part))
 		}
 	}
 
-	return true
+	return result
 }
 
-func isMatching(needToMatch map[string]any, object map[string]any) bool {
-	if needToMatch == nil || len(needToMatch) == 0 {
-		return true
+func (v valueMatcher) headerMatching(expected any, values []string) bool {
+	definition, isDefinition := expected.(map[string]any)
+
+	if isDefinition && !isOperator(definition) {
+		return false
 	}
 
-	for k, v := range needToMatch {
-		switch t := v.(type) {
-		case map[string]any:
-			{
-				inRequest, found := object[k]
-				if !found {
-					return false
-				}
+	if len(values) == 0 {
+		return isDefinition && v.matchOperators(definition, nil, false)
+	}
 
-				if casted, ok := inRequest.(map[string]any); ok {
-					if !isMatching(t, casted) {
-						return false
-					}
-				}
+	for _, value := range values {
+		if isDefinition && v.matchOperators(definition, value, true) {
+			return true
+		}
+
+		if !isDefinition && v.scalarEquals(expected, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *RequestMatcher) queryMismatches() []string {
+	result := make([]string, 0)
+	values := m.request.URL.Query()
+
+	for k, v := range m.flow.Request.Query {
+		if !queryMatching(v, values[k]) {
+			result = append(result, k)
+		}
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+func queryMatching(expected any, inRequest []string) bool {
+	found := inRequest != nil
+
+	switch t := expected.(type) {
+	case bool:
+		return found == t
+
+	case []any:
+		{
+			if !found {
+				return false
 			}
 
-		case []any:
-			{
-				inRequest, found := object[k]
-				if !found {
+			for _, elem := range t {
+				if !slices.Contains(inRequest, fmt.Sprint(elem)) {
 					return false
 				}
+			}
 
-				casted, ok := inRequest.([]any)
-				if !ok {
-					return false
-				}
+			return true
+		}
+	}
 
-				for _, elem := range t {
-					elemFound := false
-
-					for _, elemInRequest := range casted {
-						mapElem, isMapElem := elem.(map[string]any)
-						if isMapElem {
-							castedInRequest, isMap := elemInRequest.(map[string]any)
-							if !isMap {
-								break
-							}
-
-							if isMatching(mapElem, castedInRequest) {
-								elemFound = true
-								break
-							}
-						} else {
-							if elemInRequest == elem {
-								elemFound = true
-								break
-							}
-						}
-					}
-
-					if !elemFound {
-						return false
-					}
+	return found && slices.Contains(inRequest, fmt.Sprint(expected))
+}
 
-				}
+type valueMatcher struct {
+	textual bool
+	request *mapping.RequestDefinition
+}
+
+func (v valueMatcher) operatorRegexp(pattern string) *regexp.Regexp {
+	if v.request == nil {
+		compiled, _ := regexp.Compile(pattern)
+		return compiled
+	}
+
+	return v.request.OperatorRegexp(pattern)
+}
+
+func (v valueMatcher) isMatching(needToMatch map[string]any, object map[string]any) bool {
+	return len(v.bodyMismatches(needToMatch, object)) == 0
+}
+
+func (v valueMatcher) bodyMismatches(needToMatch map[string]any, object map[string]any) []string {
+	result := make([]string, 0)
+
+	for k, expected := range needToMatch {
+		result = append(result, v.fieldMismatches(k, expected, object)...)
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+func (v valueMatcher) fieldMismatches(key string, expected any, object map[string]any) []string {
+	inRequest, found := lookupField(object, key)
+
+	if definition, ok := expected.(map[string]any); ok && isOperator(definition) {
+		if !v.matchOperators(definition, inRequest, found) {
+			return []string{key}
+		}
+
+		return nil
+	}
+
+	switch t := expected.(type) {
+	case map[string]any:
+		{
+			if !found {
+				return []string{key}
 			}
 
-		default:
-			{
-				inRequest, found := object[k]
-				if !found || inRequest != t {
-					return false
+			casted, ok := inRequest.(map[string]any)
+			if !ok {
+				return nil
+			}
+
+			result := make([]string, 0)
+			for _, nested := range v.bodyMismatches(t, casted) {
+				result = append(result, key+"."+nested)
+			}
+
+			return result
+		}
+
+	case []any:
+		{
+			casted, ok := inRequest.([]any)
+			if !found || !ok {
+				return []string{key}
+			}
+
+			for _, elem := range t {
+				if !v.containsElement(casted, elem) {
+					return []string{key}
 				}
 			}
+
+			return nil
+		}
+	}
+
+	if !found || !v.scalarEquals(expected, inRequest) {
+		return []string{key}
+	}
+
+	return nil
+}
+
+func (v valueMatcher) containsElement(elements []any, expected any) bool {
+	mapElem, isMapElem := expected.(map[string]any)
+
+	for _, elemInRequest := range elements {
+		if isMapElem && isOperator(mapElem) {
+			if v.matchOperators(mapElem, elemInRequest, true) {
+				return true
+			}
+		} else if isMapElem {
+			castedInRequest, isMap := elemInRequest.(map[string]any)
+			if !isMap {
+				return false
+			}
+
+			if v.isMatching(mapElem, castedInRequest) {
+				return true
+			}
+		} else if v.scalarEquals(expected, elemInRequest) {
+			return true
 		}
 	}
 
-	return true
+	return false
+}
+
+func lookupField(object map[string]any, key string) (any, bool) {
+	if isXPathSelector(key) {
+		return selectXPath(object, key)
+	}
+
+	value, found := object[key]
+	return value, found
+}
+
+func (v valueMatcher) scalarEquals(expected any, actual any) bool {
+	switch expected.(type) {
+	case map[string]any, []any:
+		return reflect.DeepEqual(expected, actual)
+	}
+
+	switch actual.(type) {
+	case map[string]any, []any:
+		return false
+	}
+
+	if expected == actual {
+		return true
+	}
+
+	if !v.textual {
+		return false
+	}
+
+	_, expectedString := expected.(string)
+	_, actualString := actual.(string)
+
+	if expectedString == actualString {
+		return false
+	}
+
+	return fmt.Sprint(expected) == fmt.Sprint(actual)
 }
 
 func (m *RequestMatcher) IsMatch() bool {
 	return m.isMatch
 }
 
+func (m *RequestMatcher) Mismatches() []string {
+	return m.mismatches
+}
+
 var RequestMatchBuilder MatchBuilder = func(request *http.Request, flow *mapping.Flow, body map[string]any) Matcher {
 	return &RequestMatcher{request: request, flow: flow, body: body}
 }
@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
 	"github.com/stretchr/testify/require"
@@ -99,7 +100,7 @@ func TestMatch(t *testing.T) {
 			Method:  http.MethodPost,
 			Path:    "/randomPath1",
 			Body:    body,
-			Headers: map[string]string{"Content-Type": "application/json"},
+			Headers: map[string]any{"Content-Type": "application/json"},
 		},
 	}
 
@@ -108,7 +109,7 @@ func TestMatch(t *testing.T) {
 			Method:  http.MethodPost,
 			Path:    "/randomPath1",
 			Body:    map[string]any{},
-			Headers: map[string]string{"Content-Type": "application/json"},
+			Headers: map[string]any{"Content-Type": "application/json"},
 		},
 	}
 
@@ -117,7 +118,7 @@ func TestMatch(t *testing.T) {
 			Method:  http.MethodGet,
 			Path:    "/randomPath1",
 			Body:    body,
-			Headers: map[string]string{"Content-Type": "application/json"},
+			Headers: map[string]any{"Content-Type": "application/json"},
 		},
 	}
 
@@ -126,7 +127,7 @@ func TestMatch(t *testing.T) {
 			Method:  http.MethodPost,
 			Path:    "/randomPath1",
 			Body:    bodyWithArray,
-			Headers: map[string]string{"Content-Type": "application/json"},
+			Headers: map[string]any{"Content-Type": "application/json"},
 		},
 	}
 
@@ -135,7 +136,34 @@ func TestMatch(t *testing.T) {
 			Method:  http.MethodPost,
 			Path:    "/randomPath2",
 			Body:    body,
-			Headers: map[string]string{"Content-Type": "application/json"},
+			Headers: map[string]any{"Content-Type": "application/json"},
+		},
+	}
+
+	var flowTemplatedPath = mapping.Flow{
+		Request: &mapping.RequestDefinition{
+			Method:  http.MethodPost,
+			Path:    "/{first}",
+			Body:    body,
+			Headers: map[string]any{"Content-Type": "application/json"},
+		},
+	}
+
+	var flowGlobPath = mapping.Flow{
+		Request: &mapping.RequestDefinition{
+			Method:  http.MethodPost,
+			Path:    "/**",
+			Body:    body,
+			Headers: map[string]any{"Content-Type": "application/json"},
+		},
+	}
+
+	var flowTemplatedPathNotMatching = mapping.Flow{
+		Request: &mapping.RequestDefinition{
+			Method:  http.MethodPost,
+			Path:    "/{first}/{second}",
+			Body:    body,
+			Headers: map[string]any{"Content-Type": "application/json"},
 		},
 	}
 
@@ -188,6 +216,27 @@ func TestMatch(t *testing.T) {
 			flow:    flowPath2,
 			isMatch: false,
 		},
+		{
+			name:    "matches templated path",
+			request: request,
+			body:    body,
+			flow:    flowTemplatedPath,
+			isMatch: true,
+		},
+		{
+			name:    "matches glob path",
+			request: request,
+			body:    body,
+			flow:    flowGlobPath,
+			isMatch: true,
+		},
+		{
+			name:    "does not match templated path with different number of segments",
+			request: request,
+			body:    body,
+			flow:    flowTemplatedPathNotMatching,
+			isMatch: false,
+		},
 		{
 			name:    "does not match the payload if body is not matching",
 			request: requestNotMatching1,
@@ -225,3 +274,439 @@ func TestMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchQuery(t *testing.T) {
+	testCases := []struct {
+		name    string
+		url     string
+		query   map[string]any
+		isMatch bool
+	}{
+		{
+			name:    "matches exact query value",
+			url:     "/search?q=a",
+			query:   map[string]any{"q": "a"},
+			isMatch: true,
+		},
+		{
+			name:    "does not match different query value",
+			url:     "/search?q=b",
+			query:   map[string]any{"q": "a"},
+			isMatch: false,
+		},
+		{
+			name:    "matches one of repeated values",
+			url:     "/search?q=a&q=b",
+			query:   map[string]any{"q": "b"},
+			isMatch: true,
+		},
+		{
+			name:    "matches all listed values",
+			url:     "/search?tag=a&tag=b&tag=c",
+			query:   map[string]any{"tag": []any{"a", "c"}},
+			isMatch: true,
+		},
+		{
+			name:    "does not match when one of listed values is missing",
+			url:     "/search?tag=a&tag=b",
+			query:   map[string]any{"tag": []any{"a", "c"}},
+			isMatch: false,
+		},
+		{
+			name:    "matches presence of parameter",
+			url:     "/search?debug",
+			query:   map[string]any{"debug": true},
+			isMatch: true,
+		},
+		{
+			name:    "does not match absent parameter required to be present",
+			url:     "/search",
+			query:   map[string]any{"debug": true},
+			isMatch: false,
+		},
+		{
+			name:    "does not match parameter required to be absent",
+			url:     "/search?debug=1",
+			query:   map[string]any{"debug": false},
+			isMatch: false,
+		},
+		{
+			name:    "matches numeric value",
+			url:     "/search?page=2",
+			query:   map[string]any{"page": float64(2)},
+			isMatch: true,
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, v.url, nil)
+
+			flow := mapping.Flow{
+				Request: &mapping.RequestDefinition{
+					Method: http.MethodGet,
+					Path:   "/search",
+					Query:  v.query,
+				},
+			}
+
+			matcher := RequestMatcher{
+				request: request,
+				flow:    &flow,
+				body:    map[string]any{},
+			}
+
+			matcher.Match()
+
+			require.Equal(t, v.isMatch, matcher.IsMatch())
+		})
+	}
+}
+
+func TestMatchFormValues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected map[string]any
+		body     map[string]any
+		isMatch  bool
+	}{
+		{
+			name:     "matches number against form string",
+			expected: map[string]any{"amount": float64(100)},
+			body:     map[string]any{"amount": "100"},
+			isMatch:  true,
+		},
+		{
+			name:     "matches boolean against form string",
+			expected: map[string]any{"paid": true},
+			body:     map[string]any{"paid": "true"},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match different number",
+			expected: map[string]any{"amount": float64(100)},
+			body:     map[string]any{"amount": "101"},
+			isMatch:  false,
+		},
+		{
+			name:     "matches element of repeated form field",
+			expected: map[string]any{"tag": []any{float64(2)}},
+			body:     map[string]any{"tag": []any{"1", "2"}},
+			isMatch:  true,
+		},
+		{
+			name:     "matches XPath selector",
+			expected: map[string]any{"/Payment/@id": "p1", "/Payment/Amount": float64(150)},
+			body:     map[string]any{"Payment": map[string]any{"@id": "p1", "Amount": "150"}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match XPath selector with different value",
+			expected: map[string]any{"/Payment/@id": "p2"},
+			body:     map[string]any{"Payment": map[string]any{"@id": "p1", "Amount": "150"}},
+			isMatch:  false,
+		},
+		{
+			name:     "matches file metadata",
+			expected: map[string]any{"document": map[string]any{"name": "doc.txt"}},
+			body:     map[string]any{"document": map[string]any{"name": "doc.txt", "size": float64(5)}},
+			isMatch:  true,
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			require.Equal(t, v.isMatch, valueMatcher{textual: true}.isMatching(v.expected, v.body))
+		})
+	}
+}
+
+func TestMatchJSONValues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected map[string]any
+		body     map[string]any
+		isMatch  bool
+	}{
+		{
+			name:     "matches number of same type",
+			expected: map[string]any{"amount": float64(100)},
+			body:     map[string]any{"amount": float64(100)},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match number against string",
+			expected: map[string]any{"amount": float64(100)},
+			body:     map[string]any{"amount": "100"},
+			isMatch:  false,
+		},
+		{
+			name:     "does not match boolean against string",
+			expected: map[string]any{"ok": true},
+			body:     map[string]any{"ok": "true"},
+			isMatch:  false,
+		},
+		{
+			name:     "does not match array element of different type",
+			expected: map[string]any{"tag": []any{float64(2)}},
+			body:     map[string]any{"tag": []any{"1", "2"}},
+			isMatch:  false,
+		},
+		{
+			name:     "does not match $in candidate of different type",
+			expected: map[string]any{"amount": map[string]any{"$in": []any{float64(100)}}},
+			body:     map[string]any{"amount": "100"},
+			isMatch:  false,
+		},
+		{
+			name:     "matches $not argument of different type",
+			expected: map[string]any{"amount": map[string]any{"$not": float64(100)}},
+			body:     map[string]any{"amount": "100"},
+			isMatch:  true,
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			require.Equal(t, v.isMatch, valueMatcher{}.isMatching(v.expected, v.body))
+		})
+	}
+}
+
+func TestMatchHeaders(t *testing.T) {
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		expected map[string]any
+		isMatch  bool
+	}{
+		{
+			name:     "matches exact value",
+			headers:  http.Header{"X-Api-Key": []string{"abc"}},
+			expected: map[string]any{"x-api-key": "abc"},
+			isMatch:  true,
+		},
+		{
+			name:     "matches prefix",
+			headers:  http.Header{"Authorization": []string{"Bearer token123"}},
+			expected: map[string]any{"Authorization": map[string]any{"$prefix": "Bearer "}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match different prefix",
+			headers:  http.Header{"Authorization": []string{"Basic dXNlcg=="}},
+			expected: map[string]any{"Authorization": map[string]any{"$prefix": "Bearer "}},
+			isMatch:  false,
+		},
+		{
+			name:     "matches regex",
+			headers:  http.Header{"X-Request-Id": []string{"req-42"}},
+			expected: map[string]any{"X-Request-Id": map[string]any{"$regex": "^req-[0-9]+$"}},
+			isMatch:  true,
+		},
+		{
+			name:     "matches contains",
+			headers:  http.Header{"User-Agent": []string{"Mozilla/5.0 (X11; Linux)"}},
+			expected: map[string]any{"User-Agent": map[string]any{"$contains": "Linux"}},
+			isMatch:  true,
+		},
+		{
+			name:     "matches absent header",
+			headers:  http.Header{},
+			expected: map[string]any{"X-Debug": map[string]any{"$absent": true}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match present header required to be absent",
+			headers:  http.Header{"X-Debug": []string{"1"}},
+			expected: map[string]any{"X-Debug": map[string]any{"$absent": true}},
+			isMatch:  false,
+		},
+		{
+			name:     "matches one of repeated header values",
+			headers:  http.Header{"Accept": []string{"text/html", "application/json"}},
+			expected: map[string]any{"Accept": "application/json"},
+			isMatch:  true,
+		},
+		{
+			name:     "matches one of comma separated values",
+			headers:  http.Header{"Accept": []string{"text/html, application/json"}},
+			expected: map[string]any{"Accept": "application/json"},
+			isMatch:  true,
+		},
+		{
+			name:     "matches all listed values",
+			headers:  http.Header{"Accept": []string{"text/html, application/json"}},
+			expected: map[string]any{"Accept": []any{"text/html", map[string]any{"$prefix": "application/"}}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match when one of listed values is missing",
+			headers:  http.Header{"Accept": []string{"text/html"}},
+			expected: map[string]any{"Accept": []any{"text/html", "application/json"}},
+			isMatch:  false,
+		},
+		{
+			name:     "does not match missing header",
+			headers:  http.Header{},
+			expected: map[string]any{"X-Api-Key": "abc"},
+			isMatch:  false,
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, "/headers", nil)
+			request.Header = v.headers
+
+			flow := mapping.Flow{
+				Request: &mapping.RequestDefinition{
+					Method:  http.MethodGet,
+					Path:    "/headers",
+					Headers: v.expected,
+				},
+			}
+
+			matcher := RequestMatcher{
+				request: request,
+				flow:    &flow,
+				body:    map[string]any{},
+			}
+
+			matcher.Match()
+
+			require.Equal(t, v.isMatch, matcher.IsMatch())
+		})
+	}
+}
+
+func TestMismatches(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/orders?status=open", bytes.NewBufferString(""))
+	request.Header.Set("Content-Type", "text/plain")
+
+	body := map[string]any{"order": map[string]any{"amount": float64(50)}}
+
+	testCases := []struct {
+		name       string
+		definition *mapping.RequestDefinition
+		mismatches []string
+	}{
+		{
+			name:       "has no mismatches when request matches",
+			definition: &mapping.RequestDefinition{Method: http.MethodPost, Path: "/orders"},
+			mismatches: []string{},
+		},
+		{
+			name: "records every mismatch",
+			definition: &mapping.RequestDefinition{
+				Method:  http.MethodGet,
+				Path:    "/users",
+				Query:   map[string]any{"status": "closed"},
+				Body:    map[string]any{"order": map[string]any{"amount": map[string]any{"$gt": float64(100)}}},
+				Headers: map[string]any{"Content-Type": "application/json"},
+			},
+			mismatches: []string{
+				"method: expected GET but got POST",
+				"path: expected /users but got /orders",
+				"query: status",
+				"body: order.amount",
+				"header: Content-Type",
+			},
+		},
+		{
+			name: "records every mismatching key in order",
+			definition: &mapping.RequestDefinition{
+				Method: http.MethodPost,
+				Path:   "/orders",
+				Query:  map[string]any{"status": "closed", "page": "2"},
+				Body: map[string]any{
+					"order":    map[string]any{"amount": float64(100), "currency": "EUR"},
+					"customer": "c1",
+				},
+				Headers: map[string]any{"X-Tenant": "t1", "X-Api-Key": "key"},
+			},
+			mismatches: []string{
+				"query: page",
+				"query: status",
+				"body: customer",
+				"body: order.amount",
+				"body: order.currency",
+				"header: X-Api-Key",
+				"header: X-Tenant",
+			},
+		},
+		{
+			name:       "reports path pattern",
+			definition: &mapping.RequestDefinition{Method: http.MethodPost, PathPattern: "/v[0-9]+/orders"},
+			mismatches: []string{"path: expected /v[0-9]+/orders but got /orders"},
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			flow := mapping.Flow{Request: v.definition}
+
+			matcher := RequestMatcher{
+				request: request,
+				flow:    &flow,
+				body:    body,
+			}
+
+			matcher.Match()
+
+			require.Equal(t, v.mismatches, matcher.Mismatches())
+			require.Equal(t, len(v.mismatches) == 0, matcher.IsMatch())
+		})
+	}
+}
+
+func TestMatchScenario(t *testing.T) {
+	store := newScenarios()
+	store.transition("approval", "approved")
+
+	definition := &mapping.RequestDefinition{Method: http.MethodGet, Path: "/orders/1"}
+
+	testCases := []struct {
+		name       string
+		flow       mapping.Flow
+		mismatches []string
+	}{
+		{
+			name:       "matches flow without scenario",
+			flow:       mapping.Flow{Request: definition},
+			mismatches: []string{},
+		},
+		{
+			name:       "matches flow in required state",
+			flow:       mapping.Flow{Request: definition, Scenario: "approval", RequiredState: "approved"},
+			mismatches: []string{},
+		},
+		{
+			name:       "matches flow without required state",
+			flow:       mapping.Flow{Request: definition, Scenario: "approval", NewState: "approved"},
+			mismatches: []string{},
+		},
+		{
+			name:       "does not match flow in different state",
+			flow:       mapping.Flow{Request: definition, Scenario: "approval", RequiredState: mapping.StartedState},
+			mismatches: []string{"scenario approval: expected state Started but got approved"},
+		},
+		{
+			name:       "uses started state for unknown scenario",
+			flow:       mapping.Flow{Request: definition, Scenario: "shipping", RequiredState: mapping.StartedState},
+			mismatches: []string{},
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			request, _ := http.NewRequestWithContext(withScenarios(context.Background(), store), http.MethodGet, "/orders/1", nil)
+
+			matcher := RequestMatcher{request: request, flow: &v.flow, body: map[string]any{}}
+			matcher.Match()
+
+			require.Equal(t, v.mismatches, matcher.Mismatches())
+			require.Equal(t, len(v.mismatches) == 0, matcher.IsMatch())
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const Regex = "$regex"
+const GreaterThan = "$gt"
+const GreaterOrEqual = "$gte"
+const LessThan = "$lt"
+const LessOrEqual = "$lte"
+const In = "$in"
+const Exists = "$exists"
+const Type = "$type"
+const Not = "$not"
+const Prefix = "$prefix"
+const Contains = "$contains"
+const Absent = "$absent"
+
+var operators = []string{
+	Regex,
+	GreaterThan,
+	GreaterOrEqual,
+	LessThan,
+	LessOrEqual,
+	In,
+	Exists,
+	Type,
+	Not,
+	Prefix,
+	Contains,
+	Absent,
+}
+
+func isOperator(definition map[string]any) bool {
+	if len(definition) == 0 {
+		return false
+	}
+
+	for k := range definition {
+		if !isOperatorName(k) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isOperatorName(name string) bool {
+	for _, operator := range operators {
+		if operator == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v valueMatcher) matchOperators(definition map[string]any, value any, found bool) bool {
+	for operator, argument := range definition {
+		if !v.matchOperator(operator, argument, value, found) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (v valueMatcher) matchOperator(operator string, argument any, value any, found bool) bool {
+	switch operator {
+	case Exists:
+		{
+			expected, ok := argument.(bool)
+			return ok && expected == found
+		}
+
+	case Absent:
+		{
+			expected, ok := argument.(bool)
+			return ok && expected != found
+		}
+
+	case Not:
+		{
+			if nested, ok := argument.(map[string]any); ok && isOperator(nested) {
+				return !v.matchOperators(nested, value, found)
+			}
+
+			return !found || !v.valueMatches(argument, value)
+		}
+	}
+
+	if !found {
+		return false
+	}
+
+	switch operator {
+	case Regex:
+		{
+			pattern, ok := argument.(string)
+			if !ok {
+				return false
+			}
+
+			str, ok := scalarString(value)
+			if !ok {
+				return false
+			}
+
+			compiled := v.operatorRegexp(pattern)
+			return compiled != nil && compiled.MatchString(str)
+		}
+
+	case Prefix:
+		{
+			prefix, ok := argument.(string)
+			str, isScalar := scalarString(value)
+			return ok && isScalar && strings.HasPrefix(str, prefix)
+		}
+
+	case Contains:
+		{
+			if elements, ok := value.([]any); ok {
+				for _, elem := range elements {
+					if v.valueMatches(argument, elem) {
+						return true
+					}
+				}
+
+				return false
+			}
+
+			substring, ok := argument.(string)
+			str, isScalar := scalarString(value)
+			return ok && isScalar && strings.Contains(str, substring)
+		}
+
+	case GreaterThan:
+		{
+			result, ok := compare(value, argument)
+			return ok && result > 0
+		}
+
+	case GreaterOrEqual:
+		{
+			result, ok := compare(value, argument)
+			return ok && result >= 0
+		}
+
+	case LessThan:
+		{
+			result, ok := compare(value, argument)
+			return ok && result < 0
+		}
+
+	case LessOrEqual:
+		{
+			result, ok := compare(value, argument)
+			return ok && result <= 0
+		}
+
+	case In:
+		{
+			candidates, ok := argument.([]any)
+			if !ok {
+				return false
+			}
+
+			for _, candidate := range candidates {
+				if v.valueMatches(candidate, value) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+	case Type:
+		{
+			expected, ok := argument.(string)
+			return ok && typeName(value) == expected
+		}
+	}
+
+	return false
+}
+
+func (v valueMatcher) valueMatches(expected any, actual any) bool {
+	switch t := expected.(type) {
+	case map[string]any:
+		{
+			if isOperator(t) {
+				return v.matchOperators(t, actual, true)
+			}
+
+			casted, ok := actual.(map[string]any)
+			return ok && v.isMatching(t, casted)
+		}
+
+	case []any:
+		return reflect.DeepEqual(t, actual)
+	}
+
+	return v.scalarEquals(expected, actual)
+}
+
+func compare(value any, argument any) (int, bool) {
+	valueNumber, valueIsNumber := toNumber(value)
+	argumentNumber, argumentIsNumber := toNumber(argument)
+
+	if valueIsNumber && argumentIsNumber {
+		if valueNumber > argumentNumber {
+			return 1, true
+		}
+
+		if valueNumber < argumentNumber {
+			return -1, true
+		}
+
+		return 0, true
+	}
+
+	valueString, valueIsString := value.(string)
+	argumentString, argumentIsString := argument.(string)
+
+	if valueIsString && argumentIsString {
+		return strings.Compare(valueString, argumentString), true
+	}
+
+	return 0, false
+}
+
+func toNumber(value any) (float64, bool) {
+	switch t := value.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case string:
+		{
+			parsed, err := strconv.ParseFloat(t, 64)
+			return parsed, err == nil
+		}
+	}
+
+	return 0, false
+}
+
+func scalarString(value any) (string, bool) {
+	switch t := value.(type) {
+	case string:
+		return t, true
+	case map[string]any, []any, nil:
+		return "", false
+	default:
+		return fmt.Sprint(t), true
+	}
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, int:
+		return "number"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestOperators(t *testing.T) {
+	body := map[string]any{
+		"order": map[string]any{
+			"id":       "ord_123",
+			"amount":   float64(150),
+			"currency": "EUR",
+			"paid":     true,
+			"items":    []any{float64(3), float64(12)},
+			"created":  "2024-10-27T20:34:58Z",
+			"customer": map[string]any{"b": float64(2), "tier": "gold"},
+			"tags":     []any{map[string]any{"name": "vip"}, map[string]any{"name": "new"}},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		expected map[string]any
+		isMatch  bool
+	}{
+		{
+			name:     "matches regex",
+			expected: map[string]any{"order": map[string]any{"id": map[string]any{"$regex": "^ord_"}}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match regex",
+			expected: map[string]any{"order": map[string]any{"id": map[string]any{"$regex": "^inv_"}}},
+			isMatch:  false,
+		},
+		{
+			name:     "matches number greater than",
+			expected: map[string]any{"order": map[string]any{"amount": map[string]any{"$gt": float64(100)}}},
+			isMatch:  true,
+		},
+		{
+			name:     "matches range",
+			expected: map[string]any{"order": map[string]any{"amount": map[string]any{"$gte": float64(150), "$lt": float64(200)}}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match number less than",
+			expected: map[string]any{"order": map[string]any{"amount": map[string]any{"$lte": float64(100)}}},
+			isMatch:  false,
+		},
+		{
+			name:     "compares strings",
+			expected: map[string]any{"order": map[string]any{"created": map[string]any{"$gt": "2024-01-01T00:00:00Z"}}},
+			isMatch:  true,
+		},
+		{
+			name:     "matches one of values",
+			expected: map[string]any{"order": map[string]any{"currency": map[string]any{"$in": []any{"USD", "EUR"}}}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match value outside of list",
+			expected: map[string]any{"order": map[string]any{"currency": map[string]any{"$in": []any{"USD", "GBP"}}}},
+			isMatch:  false,
+		},
+		{
+			name:     "matches missing field",
+			expected: map[string]any{"order": map[string]any{"refund": map[string]any{"$exists": false}}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match existing field required to be missing",
+			expected: map[string]any{"order": map[string]any{"id": map[string]any{"$exists": false}}},
+			isMatch:  false,
+		},
+		{
+			name:     "matches type",
+			expected: map[string]any{"order": map[string]any{"amount": map[string]any{"$type": "number"}, "items": map[string]any{"$type": "array"}}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match different type",
+			expected: map[string]any{"order": map[string]any{"paid": map[string]any{"$type": "string"}}},
+			isMatch:  false,
+		},
+		{
+			name:     "negates nested operator",
+			expected: map[string]any{"order": map[string]any{"id": map[string]any{"$not": map[string]any{"$regex": "^inv_"}}}},
+			isMatch:  true,
+		},
+		{
+			name:     "negates value",
+			expected: map[string]any{"order": map[string]any{"currency": map[string]any{"$not": "EUR"}}},
+			isMatch:  false,
+		},
+		{
+			name:     "matches array element with operator",
+			expected: map[string]any{"order": map[string]any{"items": []any{map[string]any{"$gt": float64(10)}}}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match array element with operator",
+			expected: map[string]any{"order": map[string]any{"items": []any{map[string]any{"$gt": float64(20)}}}},
+			isMatch:  false,
+		},
+		{
+			name:     "negates object value",
+			expected: map[string]any{"order": map[string]any{"customer": map[string]any{"$not": map[string]any{"b": float64(1)}}}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not negate matching object value",
+			expected: map[string]any{"order": map[string]any{"customer": map[string]any{"$not": map[string]any{"b": float64(2)}}}},
+			isMatch:  false,
+		},
+		{
+			name:     "negates array value",
+			expected: map[string]any{"order": map[string]any{"items": map[string]any{"$not": []any{float64(1), float64(2)}}}},
+			isMatch:  true,
+		},
+		{
+			name:     "matches one of objects",
+			expected: map[string]any{"order": map[string]any{"customer": map[string]any{"$in": []any{map[string]any{"b": float64(1)}, map[string]any{"tier": "gold"}}}}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match object outside of list",
+			expected: map[string]any{"order": map[string]any{"customer": map[string]any{"$in": []any{map[string]any{"b": float64(3)}}}}},
+			isMatch:  false,
+		},
+		{
+			name:     "matches array containing object",
+			expected: map[string]any{"order": map[string]any{"tags": map[string]any{"$contains": map[string]any{"name": "vip"}}}},
+			isMatch:  true,
+		},
+		{
+			name:     "does not match array without object",
+			expected: map[string]any{"order": map[string]any{"tags": map[string]any{"$contains": map[string]any{"name": "old"}}}},
+			isMatch:  false,
+		},
+		{
+			name:     "treats objects with regular keys as nested objects",
+			expected: map[string]any{"order": map[string]any{"id": "ord_123", "$gt": float64(1)}},
+			isMatch:  false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.isMatch, valueMatcher{}.isMatching(test.expected, body))
+		})
+	}
+}
+
+func TestOperatorRegexp(t *testing.T) {
+	definition := &mapping.RequestDefinition{
+		Body:    map[string]any{"id": map[string]any{"$regex": "^ord_[0-9]+$"}},
+		Headers: map[string]any{"X-Id": map[string]any{"$not": map[string]any{"$regex": "^test_"}}},
+	}
+	require.NoError(t, definition.Compile())
+
+	compiled := definition.OperatorRegexp("^ord_[0-9]+$")
+	require.NotNil(t, compiled)
+	require.Same(t, compiled, definition.OperatorRegexp("^ord_[0-9]+$"))
+	require.NotNil(t, definition.OperatorRegexp("^test_"))
+
+	matcher := valueMatcher{request: definition}
+	require.True(t, matcher.isMatching(definition.Body, map[string]any{"id": "ord_1"}))
+	require.False(t, matcher.isMatching(definition.Body, map[string]any{"id": "inv_1"}))
+
+	require.Nil(t, (&mapping.RequestDefinition{}).OperatorRegexp("ord_("))
+	require.False(t, valueMatcher{}.isMatching(map[string]any{"id": map[string]any{"$regex": "ord_("}}, map[string]any{"id": "ord_("}))
+}
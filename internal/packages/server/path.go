@@ -0,0 +1,112 @@
+package server
+
+import (
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"regexp"
+	"strings"
+)
+
+const singleSegment = "*"
+const anySegments = "**"
+
+func pathParams(definition *mapping.RequestDefinition, path string) (map[string]string, bool) {
+	if definition == nil {
+		return map[string]string{}, false
+	}
+
+	if definition.PathPattern != "" {
+		return matchPathPattern(definition.PathRegexp(), path)
+	}
+
+	return matchPath(definition.Path, path)
+}
+
+func matchPathPattern(pattern *regexp.Regexp, path string) (map[string]string, bool) {
+	params := make(map[string]string)
+
+	if pattern == nil {
+		return params, false
+	}
+
+	matches := pattern.FindStringSubmatch(path)
+	if matches == nil {
+		return params, false
+	}
+
+	for index, name := range pattern.SubexpNames() {
+		if name != "" && index < len(matches) {
+			params[name] = matches[index]
+		}
+	}
+
+	return params, true
+}
+
+func matchPath(template string, path string) (map[string]string, bool) {
+	params := make(map[string]string)
+
+	if template == singleSegment {
+		return params, true
+	}
+
+	if !isTemplatedPath(template) {
+		return params, template == path
+	}
+
+	if !matchSegments(splitPath(template), splitPath(path), params) {
+		return map[string]string{}, false
+	}
+
+	return params, true
+}
+
+func isTemplatedPath(template string) bool {
+	return strings.Contains(template, "{") || strings.Contains(template, singleSegment)
+}
+
+func splitPath(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return []string{}
+	}
+
+	return strings.Split(trimmed, "/")
+}
+
+func matchSegments(template []string, path []string, params map[string]string) bool {
+	if len(template) == 0 {
+		return len(path) == 0
+	}
+
+	current := template[0]
+
+	if current == anySegments {
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(template[1:], path[i:], params) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+
+	if name, ok := paramName(current); ok {
+		params[name] = path[0]
+	} else if current != singleSegment && current != path[0] {
+		return false
+	}
+
+	return matchSegments(template[1:], path[1:], params)
+}
+
+func paramName(segment string) (string, bool) {
+	if len(segment) < 3 || !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		return "", false
+	}
+
+	return segment[1 : len(segment)-1], true
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		template string
+		path     string
+		isMatch  bool
+		params   map[string]string
+	}{
+		{
+			name:     "matches literal path",
+			template: "/users",
+			path:     "/users",
+			isMatch:  true,
+			params:   map[string]string{},
+		},
+		{
+			name:     "does not match different literal path",
+			template: "/users",
+			path:     "/users/",
+			isMatch:  false,
+			params:   map[string]string{},
+		},
+		{
+			name:     "captures path parameters",
+			template: "/users/{id}/orders/{orderId}",
+			path:     "/users/12/orders/ord_1",
+			isMatch:  true,
+			params:   map[string]string{"id": "12", "orderId": "ord_1"},
+		},
+		{
+			name:     "does not match when literal segment differs",
+			template: "/users/{id}/orders",
+			path:     "/users/12/invoices",
+			isMatch:  false,
+			params:   map[string]string{},
+		},
+		{
+			name:     "single glob matches exactly one segment",
+			template: "/users/*/orders",
+			path:     "/users/12/orders",
+			isMatch:  true,
+			params:   map[string]string{},
+		},
+		{
+			name:     "single glob does not match multiple segments",
+			template: "/users/*",
+			path:     "/users/12/orders",
+			isMatch:  false,
+			params:   map[string]string{},
+		},
+		{
+			name:     "double glob matches multiple segments",
+			template: "/users/**/{orderId}",
+			path:     "/users/12/orders/ord_1",
+			isMatch:  true,
+			params:   map[string]string{"orderId": "ord_1"},
+		},
+		{
+			name:     "single star path matches any path",
+			template: "*",
+			path:     "/users/12/orders",
+			isMatch:  true,
+			params:   map[string]string{},
+		},
+		{
+			name:     "double glob matches zero segments",
+			template: "/users/**",
+			path:     "/users",
+			isMatch:  true,
+			params:   map[string]string{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			params, isMatch := matchPath(test.template, test.path)
+
+			require.Equal(t, test.isMatch, isMatch)
+			require.Equal(t, test.params, params)
+		})
+	}
+}
+
+func TestPathParams(t *testing.T) {
+	testCases := []struct {
+		name       string
+		definition *mapping.RequestDefinition
+		path       string
+		isMatch    bool
+		params     map[string]string
+	}{
+		{
+			name:       "matches path pattern with named groups",
+			definition: &mapping.RequestDefinition{PathPattern: "/(v1|v2beta)/users/(?P<id>[0-9]+)"},
+			path:       "/v2beta/users/42",
+			isMatch:    true,
+			params:     map[string]string{"id": "42"},
+		},
+		{
+			name:       "path pattern has to match whole path",
+			definition: &mapping.RequestDefinition{PathPattern: "/v1/users"},
+			path:       "/v1/users/42",
+			isMatch:    false,
+			params:     map[string]string{},
+		},
+		{
+			name:       "path pattern takes precedence over path",
+			definition: &mapping.RequestDefinition{Path: "/users", PathPattern: "/v[0-9]+/users"},
+			path:       "/v3/users",
+			isMatch:    true,
+			params:     map[string]string{},
+		},
+		{
+			name:       "does not match invalid path pattern",
+			definition: &mapping.RequestDefinition{PathPattern: "/users/("},
+			path:       "/users/(",
+			isMatch:    false,
+			params:     map[string]string{},
+		},
+		{
+			name:       "falls back to path template",
+			definition: &mapping.RequestDefinition{Path: "/users/{id}"},
+			path:       "/users/42",
+			isMatch:    true,
+			params:     map[string]string{"id": "42"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			_ = test.definition.Compile()
+
+			params, isMatch := pathParams(test.definition, test.path)
+
+			require.Equal(t, test.isMatch, isMatch)
+			require.Equal(t, test.params, params)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"slices"
+	"strings"
+)
+
+func prioritize(flows []mapping.Flow) []mapping.Flow {
+	sorted := slices.Clone(flows)
+
+	slices.SortStableFunc(sorted, func(a mapping.Flow, b mapping.Flow) int {
+		if a.Fallback != b.Fallback {
+			if a.Fallback {
+				return 1
+			}
+
+			return -1
+		}
+
+		if a.Priority != b.Priority {
+			return b.Priority - a.Priority
+		}
+
+		specificityA := specificity(a.Request)
+		specificityB := specificity(b.Request)
+
+		if specificityA != specificityB {
+			return specificityB - specificityA
+		}
+
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return sorted
+}
+
+func specificity(definition *mapping.RequestDefinition) int {
+	if definition == nil {
+		return 0
+	}
+
+	score := 0
+
+	if definition.Method != "" {
+		score += 1
+	}
+
+	if definition.PathPattern != "" {
+		score += 1
+	} else {
+		score += pathSpecificity(definition.Path)
+	}
+
+	score += len(definition.Query)
+	score += len(definition.Headers)
+	score += bodySpecificity(definition.Body)
+
+	return score
+}
+
+func pathSpecificity(path string) int {
+	if !isTemplatedPath(path) {
+		return 3*len(splitPath(path)) + 1
+	}
+
+	score := 0
+
+	for _, segment := range splitPath(path) {
+		if segment == anySegments {
+			continue
+		}
+
+		if segment == singleSegment {
+			score += 1
+		} else if _, ok := paramName(segment); ok {
+			score += 2
+		} else {
+			score += 3
+		}
+	}
+
+	return score
+}
+
+func bodySpecificity(body map[string]any) int {
+	score := 0
+
+	for _, v := range body {
+		switch t := v.(type) {
+		case map[string]any:
+			{
+				if isOperator(t) {
+					score += 1
+				} else {
+					score += bodySpecificity(t)
+				}
+			}
+
+		case []any:
+			score += len(t)
+
+		default:
+			score += 1
+		}
+	}
+
+	return score
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -22,12 +23,22 @@ var newUUID = uuid.New
 type Replacer interface {
 	Replace(str string) (any, error)
 	Child(iterator any) Replacer
+	WithWebHookResponse(status int, header http.Header, body map[string]any) Replacer
+}
+
+type webHookResponse struct {
+	status int
+	header http.Header
+	body   map[string]any
 }
 
 type stringReplacer struct {
 	body     map[string]any
 	header   http.Header
+	path     map[string]string
+	query    url.Values
 	iterator any
+	webHook  *webHookResponse
 }
 
 func (s stringReplacer) Replace(str string) (any, error) {
@@ -41,7 +52,7 @@ func (s stringReplacer) Replace(str string) (any, error) {
 		return str, nil
 	}
 
-	if len(matches) == 1 {
+	if len(matches) == 1 && matches[0] == str {
 		return s.doReplacement(matches[0])
 	}
 
@@ -61,12 +72,20 @@ func (s stringReplacer) Child(iterator any) Replacer {
 	replacer := stringReplacer{
 		body:     s.body,
 		header:   s.header,
+		path:     s.path,
+		query:    s.query,
 		iterator: iterator,
+		webHook:  s.webHook,
 	}
 
 	return replacer
 }
 
+func (s stringReplacer) WithWebHookResponse(status int, header http.Header, body map[string]any) Replacer {
+	s.webHook = &webHookResponse{status: status, header: header, body: body}
+	return s
+}
+
 func (s stringReplacer) doReplacement(variable string) (any, error) {
 	variable = variable[3 : len(variable)-2]
 	if strings.HasPrefix(variable, "body.") {
@@ -108,6 +127,33 @@ func (s stringReplacer) doReplacement(variable string) (any, error) {
 		return s.getFromHeader(value)
 	}
 
+	if strings.HasPrefix(variable, "path.") {
+		value, prefixFound := strings.CutPrefix(variable, "path.")
+		if !prefixFound {
+			return "", errors.New("unable to cut path. from" + variable)
+		}
+
+		return s.getFromPath(value)
+	}
+
+	if strings.HasPrefix(variable, "query.") {
+		value, prefixFound := strings.CutPrefix(variable, "query.")
+		if !prefixFound {
+			return "", errors.New("unable to cut query. from" + variable)
+		}
+
+		return s.getFromQuery(value)
+	}
+
+	if strings.HasPrefix(variable, "webhook.response.") {
+		value, prefixFound := strings.CutPrefix(variable, "webhook.response.")
+		if !prefixFound {
+			return "", errors.New("unable to cut webhook.response. from" + variable)
+		}
+
+		return s.getFromWebHookResponse(value)
+	}
+
 	if variable == "now" {
 		return s.getCurrentDate(), nil
 	}
@@ -290,9 +336,40 @@ func (s stringReplacer) getFromIterator(value string) (any, error) {
 }
 
 func (s stringReplacer) getFromBody(value string) (any, error) {
+	return getFromMap(s.body, value)
+}
+
+func (s stringReplacer) getFromWebHookResponse(value string) (any, error) {
+	if s.webHook == nil {
+		return "", errors.New("no webhook response found")
+	}
+
+	if value == "status" {
+		return s.webHook.status, nil
+	}
+
+	if strings.HasPrefix(value, "header.") {
+		name, _ := strings.CutPrefix(value, "header.")
+
+		val := s.webHook.header.Get(name)
+		if val == "" {
+			return "", errors.New("cant find in webhook response header " + name)
+		}
+
+		return val, nil
+	}
+
+	if strings.HasPrefix(value, "body.") {
+		path, _ := strings.CutPrefix(value, "body.")
+		return getFromMap(s.webHook.body, path)
+	}
+
+	return "", errors.New("unknown webhook response field " + value)
+}
+
+func getFromMap(current map[string]any, value string) (any, error) {
 	segments := strings.Split(value, ".")
 
-	current := s.body
 	length := len(segments)
 
 	for k, v := range segments {
@@ -328,6 +405,24 @@ func (s stringReplacer) getFromHeader(value string) (any, error) {
 	return val, nil
 }
 
-func NewReplacer(body map[string]any, header http.Header) Replacer {
-	return stringReplacer{body: body, header: header}
+func (s stringReplacer) getFromPath(value string) (any, error) {
+	val, found := s.path[value]
+
+	if !found {
+		return "", errors.New("cant find in path " + value)
+	}
+
+	return val, nil
+}
+
+func (s stringReplacer) getFromQuery(value string) (any, error) {
+	if !s.query.Has(value) {
+		return "", errors.New("cant find in query " + value)
+	}
+
+	return s.query.Get(value), nil
+}
+
+func NewReplacer(body map[string]any, header http.Header, path map[string]string, query url.Values) Replacer {
+	return stringReplacer{body: body, header: header, path: path, query: query}
 }
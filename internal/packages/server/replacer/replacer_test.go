@@ -5,6 +5,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 )
@@ -22,9 +23,12 @@ func TestReplace(t *testing.T) {
 		name     string
 		body     map[string]any
 		headers  map[string]string
+		path     map[string]string
+		query    url.Values
 		input    string
 		result   any
 		iterator any
+		webHook  *webHookResponse
 	}{
 		{
 			name:    "no replacement if no variable",
@@ -112,6 +116,60 @@ func TestReplace(t *testing.T) {
 			input:    "${{iterator.}}",
 			result:   "whole",
 		},
+		{
+			name:    "replaces from path parameters",
+			body:    map[string]any{},
+			headers: map[string]string{},
+			path:    map[string]string{"id": "42"},
+			input:   "${{path.id}}",
+			result:  "42",
+		},
+		{
+			name:    "replaces from query",
+			body:    map[string]any{},
+			headers: map[string]string{},
+			query:   url.Values{"q": []string{"first", "second"}},
+			input:   "search ${{query.q}} and ${{query.q}}",
+			result:  "search first and first",
+		},
+		{
+			name:    "replaces single variable inside text",
+			body:    map[string]any{"name": "Jon"},
+			headers: map[string]string{},
+			input:   "Hello ${{body.name}}!",
+			result:  "Hello Jon!",
+		},
+		{
+			name:    "replaces webhook response status",
+			body:    map[string]any{},
+			headers: map[string]string{},
+			input:   "${{webhook.response.status}}",
+			result:  201,
+			webHook: &webHookResponse{status: 201},
+		},
+		{
+			name:    "replaces from webhook response body",
+			body:    map[string]any{},
+			headers: map[string]string{},
+			input:   "${{webhook.response.body.payment.id}}",
+			result:  "pay_1",
+			webHook: &webHookResponse{body: map[string]any{"payment": map[string]any{"id": "pay_1"}}},
+		},
+		{
+			name:    "replaces from webhook response header",
+			body:    map[string]any{},
+			headers: map[string]string{},
+			input:   "${{webhook.response.header.Location}}",
+			result:  "/payments/1",
+			webHook: &webHookResponse{header: http.Header{"Location": []string{"/payments/1"}}},
+		},
+		{
+			name:    "no webhook response outside of chained webhook",
+			body:    map[string]any{},
+			headers: map[string]string{},
+			input:   "${{webhook.response.status}}",
+			result:  "",
+		},
 	}
 
 	for _, test := range testCases {
@@ -125,7 +183,10 @@ func TestReplace(t *testing.T) {
 			replacer := stringReplacer{
 				body:     test.body,
 				header:   req.Header,
+				path:     test.path,
+				query:    test.query,
 				iterator: test.iterator,
+				webHook:  test.webHook,
 			}
 
 			result, _ := replacer.Replace(test.input)
@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
 	"github.com/djordjev/webhook-simulator/internal/packages/server/replacer"
 	"io"
 	"log"
 	"maps"
 	"net/http"
+	"net/url"
 	"reflect"
+	"regexp"
 	"sync"
 	"time"
 )
@@ -19,6 +23,8 @@ const Each = "$each"
 const Field = "$field"
 const To = "$to"
 
+var variableRegexp = regexp.MustCompile(replacer.VariableRegexp)
+
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
@@ -38,6 +44,10 @@ type RequestResponder struct {
 }
 
 func (r RequestResponder) Respond() {
+	if r.flow.Scenario != "" && r.flow.NewState != "" {
+		transitionScenario(r.request.Context(), r.flow.Scenario, r.flow.NewState)
+	}
+
 	reqDelay := time.Duration(r.flow.Response.Delay)
 
 	var wg sync.WaitGroup
@@ -60,35 +70,47 @@ func (r RequestResponder) Respond() {
 		}
 	}()
 
-	if r.flow.WebHook != nil {
-		webhookDelay := time.Duration(r.flow.WebHook.Delay)
+	r.triggerWebHooks()
 
-		go func() {
+	wg.Wait()
 
-			select {
-			case <-time.After(webhookDelay * time.Millisecond):
-				{
-					r.triggerWebHook()
-				}
+}
 
-			case <-r.mainCtx.Done():
-				{
-					log.Println("canceling timeout for webhook")
-					return
-				}
-			}
-		}()
+func (r RequestResponder) triggerWebHooks() {
+	for _, hook := range r.flow.AllWebHooks() {
+		go r.scheduleWebHook(hook)
 	}
+}
 
-	wg.Wait()
+func (r RequestResponder) scheduleWebHook(hook *mapping.WebHookDefinition) {
+	webhookDelay := time.Duration(hook.Delay)
+
+	select {
+	case <-time.After(webhookDelay * time.Millisecond):
+		{
+			r.triggerWebHook(hook)
+		}
 
+	case <-r.mainCtx.Done():
+		{
+			log.Println("canceling timeout for webhook")
+			return
+		}
+	}
 }
 
 func (r RequestResponder) respondHttp() {
-	payload := r.constructPayload(
-		r.flow.Response.IncludeRequest,
-		r.flow.Response.Body,
-	)
+	var payload []byte
+
+	if r.flow.Response.Format == mapping.XMLFormat {
+		payload = r.renderTemplate(r.flow.Response.Template, escapeXML)
+		r.rw.Header().Set("Content-Type", "application/xml")
+	} else {
+		payload = r.constructPayload(
+			r.flow.Response.IncludeRequest,
+			r.flow.Response.Body,
+		)
+	}
 
 	for k, v := range r.flow.Response.Headers {
 		replaced, _ := r.replacer.Replace(v)
@@ -109,49 +131,165 @@ func (r RequestResponder) respondHttp() {
 	}
 }
 
-func (r RequestResponder) triggerWebHook() {
+func (r RequestResponder) triggerWebHook(hook *mapping.WebHookDefinition) {
 	payload := r.constructPayload(
-		r.flow.WebHook.IncludeRequest,
-		r.flow.WebHook.Body,
+		hook.IncludeRequest,
+		hook.Body,
 	)
 
-	for k, v := range r.flow.WebHook.Headers {
-		replaced, _ := r.replacer.Replace(v)
-		if strReplaced, ok := replaced.(string); ok {
-			r.rw.Header().Set(k, strReplaced)
+	headers := r.webHookHeaders(hook)
+
+	target, err := r.webHookURL(hook)
+	if err != nil {
+		log.Println("invalid webhook url", err)
+		return
+	}
+
+	retry := hook.Retry
+
+	for attempt := 1; ; attempt++ {
+		response, ok := r.sendWebHook(hook, target, headers, payload)
+		if ok {
+			r.triggerFollowUps(hook, response)
+			return
+		}
+
+		if attempt >= maxAttempts(retry) {
+			return
+		}
+
+		delay := retryDelay(retry, attempt)
+		log.Println(fmt.Sprintf("webhook attempt %d failed, retrying in %s", attempt, delay))
+
+		select {
+		case <-time.After(delay):
+			{
+				continue
+			}
+
+		case <-r.mainCtx.Done():
+			{
+				log.Println("canceling webhook retries")
+				return
+			}
+		}
+	}
+}
+
+func (r RequestResponder) triggerFollowUps(hook *mapping.WebHookDefinition, response *http.Response) {
+	if len(hook.Then) == 0 {
+		return
+	}
+
+	body := make(map[string]any)
+	if response.Body != nil {
+		data, _ := io.ReadAll(response.Body)
+		if err := json.Unmarshal(data, &body); err != nil && len(data) > 0 {
+			body = map[string]any{RawBody: string(data)}
 		}
 	}
 
+	next := r
+	next.replacer = r.replacer.WithWebHookResponse(response.StatusCode, response.Header, body)
+
+	for i := range hook.Then {
+		go next.scheduleWebHook(&hook.Then[i])
+	}
+}
+
+func (r RequestResponder) webHookHeaders(hook *mapping.WebHookDefinition) http.Header {
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+
+	for k, v := range hook.Headers {
+		replaced, _ := r.replacer.Replace(v)
+		headers.Set(k, fmt.Sprint(replaced))
+	}
+
+	return headers
+}
+
+func (r RequestResponder) webHookURL(hook *mapping.WebHookDefinition) (string, error) {
+	replaced, err := r.replacer.Replace(hook.Path)
+	if err != nil {
+		return "", err
+	}
+
+	if len(hook.Query) == 0 {
+		return fmt.Sprint(replaced), nil
+	}
+
+	target, err := url.Parse(fmt.Sprint(replaced))
+	if err != nil {
+		return "", err
+	}
+
+	query := target.Query()
+	for k, v := range hook.Query {
+		value, _ := r.replacer.Replace(v)
+		query.Set(k, fmt.Sprint(value))
+	}
+
+	target.RawQuery = query.Encode()
+
+	return target.String(), nil
+}
+
+func (r RequestResponder) sendWebHook(hook *mapping.WebHookDefinition, target string, headers http.Header, payload []byte) (*http.Response, bool) {
 	log.Println("sending webhook request" + string(payload))
 
 	body := bytes.NewReader(payload)
 
-	req, err := http.NewRequest(r.flow.WebHook.Method, r.flow.WebHook.Path, body)
+	req, err := http.NewRequestWithContext(
+		withClient(withFlow(context.Background(), r.flow.ID), hook.Client),
+		hook.Method,
+		target,
+		body,
+	)
 
 	if err != nil {
 		log.Println("unable to create request for webhook")
+		return nil, false
+	}
+
+	req.Header = headers.Clone()
+
+	if signing := hook.Signing; signing != nil {
+		headers, err := signatureHeaders(signing, payload, now())
+		if err != nil {
+			log.Println("unable to sign webhook request", err)
+			return nil, false
+		}
+
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
 	}
 
 	res, err := r.httpClient.Do(req)
 	if err != nil || res == nil {
 		log.Println("error while receiving webhook response", err)
-		return
+		return nil, false
 	}
 
 	resBody, err := io.ReadAll(res.Body)
 	if err != nil {
 		log.Println("unable to read webhook response body", res.StatusCode)
-		return
+		return nil, false
 	}
 
 	log.Println("received response from webhook", "status code", res.StatusCode, string(resBody))
+
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	return res, isSuccessful(hook.Retry, res.StatusCode)
 }
 
 func (r RequestResponder) constructPayload(includeRequest bool, data map[string]any) []byte {
 	response := make(map[string]any)
 
 	if includeRequest {
-		maps.Copy(response, r.body)
+		maps.Copy(response, deepCopy(r.body))
 	}
 
 	err := r.mergeInto(response, data)
@@ -168,6 +306,30 @@ func (r RequestResponder) constructPayload(includeRequest bool, data map[string]
 	return marshalled
 }
 
+func (r RequestResponder) renderTemplate(template string, escape func(string) string) []byte {
+	rendered := variableRegexp.ReplaceAllStringFunc(template, func(variable string) string {
+		replaced, err := r.replacer.Replace(variable)
+		if err != nil {
+			return ""
+		}
+
+		return escape(fmt.Sprint(replaced))
+	})
+
+	return []byte(rendered)
+}
+
+func escapeXML(value string) string {
+	var buffer bytes.Buffer
+
+	err := xml.EscapeText(&buffer, []byte(value))
+	if err != nil {
+		return ""
+	}
+
+	return buffer.String()
+}
+
 func (r RequestResponder) mustMapStringAny(unknown any) map[string]any {
 	result := make(map[string]any)
 
@@ -402,6 +564,8 @@ var RequestResponseBuilder ResponseBuilder = func(
 	mainCtx context.Context,
 	httpClient HTTPClient,
 ) Responder {
+	params, _ := pathParams(flow.Request, request.URL.Path)
+
 	return RequestResponder{
 		request:    request,
 		flow:       flow,
@@ -409,7 +573,7 @@ var RequestResponseBuilder ResponseBuilder = func(
 		rw:         rw,
 		mainCtx:    mainCtx,
 		httpClient: httpClient,
-		replacer:   replacer.NewReplacer(body, request.Header),
+		replacer:   replacer.NewReplacer(body, request.Header, params, request.URL.Query()),
 	}
 }
 
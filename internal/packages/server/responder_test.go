@@ -13,6 +13,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var payloadResponderReq = `
@@ -358,3 +359,429 @@ func TestResponder(t *testing.T) {
 	}
 
 }
+
+func TestResponderXML(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/payments", bytes.NewBufferString(""))
+	request.Header.Set("Content-Type", "text/xml")
+
+	body := map[string]any{
+		"Envelope": map[string]any{
+			"Body": map[string]any{
+				"Payment": map[string]any{"@id": "p1", "Note": "Tom & Jerry"},
+			},
+		},
+	}
+
+	flow := mapping.Flow{
+		Response: &mapping.ResponseDefinition{
+			Code:     http.StatusOK,
+			Format:   mapping.XMLFormat,
+			Template: `<Result id="${{body.Envelope.Body.Payment.@id}}"><Note>${{body.Envelope.Body.Payment.Note}}</Note></Result>`,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	response := httptest.NewRecorder()
+
+	responder := RequestResponseBuilder(
+		request,
+		&flow,
+		body,
+		response,
+		ctx,
+		&mockHttpClient{},
+	)
+
+	responder.Respond()
+
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, "application/xml", response.Header().Get("Content-Type"))
+	require.Equal(t, `<Result id="p1"><Note>Tom &amp; Jerry</Note></Result>`, response.Body.String())
+}
+
+func TestResponderRetries(t *testing.T) {
+	newResponse := func(status int) *http.Response {
+		return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewBufferString(""))}
+	}
+
+	testCases := []struct {
+		name          string
+		retry         *mapping.RetryDefinition
+		responses     []*http.Response
+		canceled      bool
+		expectedCalls int
+	}{
+		{
+			name:          "sends webhook once without retry definition",
+			responses:     []*http.Response{newResponse(http.StatusInternalServerError)},
+			expectedCalls: 1,
+		},
+		{
+			name:          "retries until webhook succeeds",
+			retry:         &mapping.RetryDefinition{MaxAttempts: 5, Interval: 1},
+			responses:     []*http.Response{newResponse(http.StatusInternalServerError), newResponse(http.StatusBadGateway), newResponse(http.StatusOK)},
+			expectedCalls: 3,
+		},
+		{
+			name:          "stops after max attempts",
+			retry:         &mapping.RetryDefinition{MaxAttempts: 2, Backoff: mapping.ExponentialBackoff, Interval: 1},
+			responses:     []*http.Response{newResponse(http.StatusInternalServerError), newResponse(http.StatusInternalServerError)},
+			expectedCalls: 2,
+		},
+		{
+			name:          "uses configured success codes",
+			retry:         &mapping.RetryDefinition{MaxAttempts: 3, Interval: 1, SuccessCodes: []int{http.StatusConflict}},
+			responses:     []*http.Response{newResponse(http.StatusOK), newResponse(http.StatusConflict)},
+			expectedCalls: 2,
+		},
+		{
+			name:          "stops retrying when application is shutting down",
+			retry:         &mapping.RetryDefinition{MaxAttempts: 3, Interval: 60000},
+			responses:     []*http.Response{newResponse(http.StatusInternalServerError)},
+			canceled:      true,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if v.canceled {
+				cancel()
+			}
+
+			request, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+
+			flow := mapping.Flow{
+				Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+				WebHook: &mapping.WebHookDefinition{
+					Method: http.MethodPost,
+					Path:   "http://receiver/events",
+					Body:   map[string]any{"ok": true},
+					Retry:  v.retry,
+				},
+			}
+
+			mocked := mockHttpClient{}
+			for _, response := range v.responses {
+				mocked.On("Do", mock.Anything).Return(response, nil).Once()
+			}
+
+			responder := RequestResponseBuilder(
+				request,
+				&flow,
+				map[string]any{},
+				httptest.NewRecorder(),
+				ctx,
+				&mocked,
+			)
+
+			responder.(RequestResponder).triggerWebHook(flow.WebHook)
+
+			mocked.AssertNumberOfCalls(t, "Do", v.expectedCalls)
+		})
+	}
+}
+
+func TestResponderSigning(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+
+	flow := mapping.Flow{
+		Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+		WebHook: &mapping.WebHookDefinition{
+			Method: http.MethodPost,
+			Path:   "http://receiver/events",
+			Body:   map[string]any{"id": float64(1)},
+			Retry:  &mapping.RetryDefinition{MaxAttempts: 2, Interval: 1},
+			Signing: &mapping.SigningDefinition{
+				Secret: "secret",
+				Header: "X-Hub-Signature-256",
+				Format: "sha256={signature}",
+			},
+		},
+	}
+
+	mocked := mockHttpClient{}
+	mocked.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.Header.Get("X-Hub-Signature-256") == "sha256=03def589620c813f198fd03d7967e292b163ef0435ebf43071ce0e9519763cb7"
+	})).Return(&http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(bytes.NewBufferString(""))}, nil)
+
+	responder := RequestResponseBuilder(request, &flow, map[string]any{}, httptest.NewRecorder(), context.Background(), &mocked)
+	responder.(RequestResponder).triggerWebHook(flow.WebHook)
+
+	mocked.AssertNumberOfCalls(t, "Do", 2)
+}
+
+func TestResponderMultipleWebHooks(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+
+	flow := mapping.Flow{
+		ID:       "orders",
+		Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+		WebHook:  &mapping.WebHookDefinition{Method: http.MethodPost, Path: "http://billing/events"},
+		WebHooks: []mapping.WebHookDefinition{
+			{Method: http.MethodPost, Path: "http://audit/events", Delay: 10},
+			{Method: http.MethodPut, Path: "http://notifications/events", Body: map[string]any{"type": "order"}},
+		},
+	}
+
+	mocked := mockHttpClient{}
+	for _, hook := range flow.AllWebHooks() {
+		mocked.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == hook.Path
+		})).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(""))}, nil).Once()
+	}
+
+	deliveries := newDeliveryLog(10)
+	client := recordingClient{client: &mocked, log: deliveries}
+
+	responder := RequestResponseBuilder(request, &flow, map[string]any{}, httptest.NewRecorder(), context.Background(), client)
+	responder.Respond()
+
+	require.Eventually(t, func() bool {
+		return len(deliveries.find(deliveryFilter{flow: "orders"})) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	urls := make([]string, 0)
+	for _, delivery := range deliveries.find(deliveryFilter{}) {
+		urls = append(urls, delivery.Method+" "+delivery.URL)
+	}
+
+	require.ElementsMatch(t, []string{
+		"POST http://billing/events",
+		"POST http://audit/events",
+		"PUT http://notifications/events",
+	}, urls)
+}
+
+func TestResponderWebHooksIncludeRequest(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+	body := map[string]any{"user": map[string]any{"name": "Jon"}}
+
+	flow := mapping.Flow{
+		ID:       "orders",
+		Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+		WebHooks: []mapping.WebHookDefinition{
+			{Method: http.MethodPost, Path: "http://billing/events", IncludeRequest: true, Body: map[string]any{"user": map[string]any{"plan": "pro"}}},
+			{Method: http.MethodPost, Path: "http://audit/events", IncludeRequest: true, Body: map[string]any{"user": map[string]any{"action": "created"}}},
+		},
+	}
+
+	mocked := mockHttpClient{}
+	for _, hook := range flow.AllWebHooks() {
+		mocked.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == hook.Path
+		})).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(""))}, nil).Once()
+	}
+
+	deliveries := newDeliveryLog(10)
+	client := recordingClient{client: &mocked, log: deliveries}
+
+	responder := RequestResponseBuilder(request, &flow, body, httptest.NewRecorder(), context.Background(), client)
+	responder.Respond()
+
+	require.Eventually(t, func() bool {
+		return len(deliveries.find(deliveryFilter{flow: "orders"})) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	payloads := make(map[string]string)
+	for _, delivery := range deliveries.find(deliveryFilter{}) {
+		payloads[delivery.URL] = delivery.Payload
+	}
+
+	require.JSONEq(t, `{"user": {"name": "Jon", "plan": "pro"}}`, payloads["http://billing/events"])
+	require.JSONEq(t, `{"user": {"name": "Jon", "action": "created"}}`, payloads["http://audit/events"])
+	require.Equal(t, map[string]any{"user": map[string]any{"name": "Jon"}}, body)
+}
+
+func TestResponderChainedWebHooks(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+
+	flow := mapping.Flow{
+		ID:       "orders",
+		Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+		WebHook: &mapping.WebHookDefinition{
+			Method: http.MethodPost,
+			Path:   "http://payments/events",
+			Then: []mapping.WebHookDefinition{
+				{
+					Method: http.MethodPost,
+					Path:   "http://receipts/events",
+					Body: map[string]any{
+						"payment": "${{webhook.response.body.id}}",
+						"status":  "${{webhook.response.status}}",
+					},
+				},
+			},
+		},
+	}
+
+	mocked := mockHttpClient{}
+	mocked.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.Host == "payments"
+	})).Return(&http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(`{"id": "pay_1"}`))}, nil).Once()
+	mocked.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.Host == "receipts"
+	})).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(""))}, nil).Once()
+
+	deliveries := newDeliveryLog(10)
+	client := recordingClient{client: &mocked, log: deliveries}
+
+	responder := RequestResponseBuilder(request, &flow, map[string]any{}, httptest.NewRecorder(), context.Background(), client)
+	responder.Respond()
+
+	require.Eventually(t, func() bool {
+		return len(deliveries.find(deliveryFilter{url: "receipts"})) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	receipt := deliveries.find(deliveryFilter{url: "receipts"})[0]
+	require.JSONEq(t, `{"payment": "pay_1", "status": 201}`, receipt.Payload)
+}
+
+func TestResponderChainedWebHooksNotTriggeredOnFailure(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+
+	flow := mapping.Flow{
+		Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+		WebHook: &mapping.WebHookDefinition{
+			Method: http.MethodPost,
+			Path:   "http://payments/events",
+			Then:   []mapping.WebHookDefinition{{Method: http.MethodPost, Path: "http://receipts/events"}},
+		},
+	}
+
+	mocked := mockHttpClient{}
+	mocked.On("Do", mock.Anything).Return(&http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(bytes.NewBufferString(""))}, nil)
+
+	responder := RequestResponseBuilder(request, &flow, map[string]any{}, httptest.NewRecorder(), context.Background(), &mocked)
+	responder.(RequestResponder).triggerWebHook(flow.WebHook)
+
+	time.Sleep(20 * time.Millisecond)
+	mocked.AssertNumberOfCalls(t, "Do", 1)
+}
+
+func TestResponderWebHookHeaders(t *testing.T) {
+	testCases := []struct {
+		name     string
+		headers  map[string]string
+		expected map[string]string
+	}{
+		{
+			name:     "sends default content type",
+			expected: map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name:    "sends templated headers",
+			headers: map[string]string{"Authorization": "Bearer ${{header.X-Token}}", "X-Order": "${{path.id}}"},
+			expected: map[string]string{
+				"Content-Type":  "application/json",
+				"Authorization": "Bearer abc",
+				"X-Order":       "42",
+			},
+		},
+		{
+			name:     "overrides content type",
+			headers:  map[string]string{"Content-Type": "text/plain"},
+			expected: map[string]string{"Content-Type": "text/plain"},
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodPost, "/orders/42", nil)
+			request.Header.Set("X-Token", "abc")
+
+			flow := mapping.Flow{
+				Request:  &mapping.RequestDefinition{Path: "/orders/{id}"},
+				Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+				WebHook: &mapping.WebHookDefinition{
+					Method:  http.MethodPost,
+					Path:    "http://receiver/events",
+					Headers: v.headers,
+				},
+			}
+
+			mocked := mockHttpClient{}
+			mocked.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				for key, value := range v.expected {
+					if req.Header.Get(key) != value {
+						return false
+					}
+				}
+
+				return true
+			})).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(""))}, nil)
+
+			recorder := httptest.NewRecorder()
+
+			responder := RequestResponseBuilder(request, &flow, map[string]any{}, recorder, context.Background(), &mocked)
+			responder.(RequestResponder).triggerWebHook(flow.WebHook)
+
+			mocked.AssertNumberOfCalls(t, "Do", 1)
+			require.Empty(t, recorder.Header())
+		})
+	}
+}
+
+func TestResponderWebHookURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		query    map[string]string
+		expected string
+	}{
+		{
+			name:     "uses url verbatim",
+			path:     "http://receiver/events",
+			expected: "http://receiver/events",
+		},
+		{
+			name:     "uses callback url from request body",
+			path:     "${{body.callback_url}}",
+			expected: "http://client/callback",
+		},
+		{
+			name:     "templates url segments",
+			path:     "http://receiver/orders/${{path.id}}/events",
+			expected: "http://receiver/orders/42/events",
+		},
+		{
+			name:     "adds templated query parameters",
+			path:     "http://receiver/events?source=simulator",
+			query:    map[string]string{"order": "${{path.id}}", "status": "${{query.status}}"},
+			expected: "http://receiver/events?order=42&source=simulator&status=paid",
+		},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodPost, "/orders/42?status=paid", nil)
+
+			flow := mapping.Flow{
+				Request:  &mapping.RequestDefinition{Path: "/orders/{id}"},
+				Response: &mapping.ResponseDefinition{Code: http.StatusOK},
+				WebHook: &mapping.WebHookDefinition{
+					Method: http.MethodPost,
+					Path:   v.path,
+					Query:  v.query,
+				},
+			}
+
+			mocked := mockHttpClient{}
+			mocked.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				return req.URL.String() == v.expected
+			})).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(""))}, nil)
+
+			body := map[string]any{"callback_url": "http://client/callback"}
+
+			responder := RequestResponseBuilder(request, &flow, body, httptest.NewRecorder(), context.Background(), &mocked)
+			responder.(RequestResponder).triggerWebHook(flow.WebHook)
+
+			mocked.AssertNumberOfCalls(t, "Do", 1)
+		})
+	}
+}
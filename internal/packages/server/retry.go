@@ -0,0 +1,64 @@
+package server
+
+import (
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+const defaultRetryInterval = 1000
+const defaultMaxRetryInterval = 300000
+
+var jitter = rand.Intn
+
+func maxAttempts(retry *mapping.RetryDefinition) int {
+	if retry == nil || retry.MaxAttempts < 1 {
+		return 1
+	}
+
+	return retry.MaxAttempts
+}
+
+func retryDelay(retry *mapping.RetryDefinition, attempt int) time.Duration {
+	interval := retry.Interval
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+
+	delay := interval
+
+	if retry.Backoff == mapping.ExponentialBackoff {
+		limit := retry.MaxInterval
+		if limit <= 0 {
+			limit = max(interval, defaultMaxRetryInterval)
+		}
+
+		for i := 1; i < attempt && delay < limit; i++ {
+			if delay > limit/2 {
+				delay = limit
+				break
+			}
+
+			delay *= 2
+		}
+	}
+
+	if retry.MaxInterval > 0 && delay > retry.MaxInterval {
+		delay = retry.MaxInterval
+	}
+
+	if retry.Jitter > 0 {
+		delay += jitter(retry.Jitter + 1)
+	}
+
+	return time.Duration(delay) * time.Millisecond
+}
+
+func isSuccessful(retry *mapping.RetryDefinition, status int) bool {
+	if retry != nil && len(retry.SuccessCodes) > 0 {
+		return slices.Contains(retry.SuccessCodes, status)
+	}
+
+	return status >= 200 && status < 300
+}
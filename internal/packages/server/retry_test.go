@@ -0,0 +1,94 @@
+package server
+
+import (
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"github.com/stretchr/testify/require"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	original := jitter
+	jitter = func(n int) int { return n - 1 }
+	defer func() {
+		jitter = original
+	}()
+
+	testCases := []struct {
+		name    string
+		retry   mapping.RetryDefinition
+		attempt int
+		delay   time.Duration
+	}{
+		{
+			name:    "uses default interval",
+			retry:   mapping.RetryDefinition{},
+			attempt: 3,
+			delay:   time.Second,
+		},
+		{
+			name:    "uses fixed interval",
+			retry:   mapping.RetryDefinition{Backoff: mapping.FixedBackoff, Interval: 200},
+			attempt: 3,
+			delay:   200 * time.Millisecond,
+		},
+		{
+			name:    "doubles exponential interval",
+			retry:   mapping.RetryDefinition{Backoff: mapping.ExponentialBackoff, Interval: 200},
+			attempt: 3,
+			delay:   800 * time.Millisecond,
+		},
+		{
+			name:    "caps exponential interval",
+			retry:   mapping.RetryDefinition{Backoff: mapping.ExponentialBackoff, Interval: 200, MaxInterval: 500},
+			attempt: 3,
+			delay:   500 * time.Millisecond,
+		},
+		{
+			name:    "caps exponential interval at default maximum",
+			retry:   mapping.RetryDefinition{Backoff: mapping.ExponentialBackoff, Interval: 200},
+			attempt: 100,
+			delay:   5 * time.Minute,
+		},
+		{
+			name:    "does not overflow exponential interval with large maximum",
+			retry:   mapping.RetryDefinition{Backoff: mapping.ExponentialBackoff, Interval: 200, MaxInterval: math.MaxInt / int(time.Millisecond)},
+			attempt: 100,
+			delay:   time.Duration(math.MaxInt/int(time.Millisecond)) * time.Millisecond,
+		},
+		{
+			name:    "does not cap interval larger than default maximum",
+			retry:   mapping.RetryDefinition{Backoff: mapping.ExponentialBackoff, Interval: 600000},
+			attempt: 3,
+			delay:   10 * time.Minute,
+		},
+		{
+			name:    "adds jitter",
+			retry:   mapping.RetryDefinition{Interval: 200, Jitter: 50},
+			attempt: 1,
+			delay:   250 * time.Millisecond,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.delay, retryDelay(&test.retry, test.attempt))
+		})
+	}
+}
+
+func TestIsSuccessful(t *testing.T) {
+	require.True(t, isSuccessful(nil, http.StatusOK))
+	require.True(t, isSuccessful(nil, http.StatusNoContent))
+	require.False(t, isSuccessful(nil, http.StatusInternalServerError))
+
+	retry := &mapping.RetryDefinition{SuccessCodes: []int{http.StatusOK, http.StatusConflict}}
+	require.True(t, isSuccessful(retry, http.StatusConflict))
+	require.False(t, isSuccessful(retry, http.StatusCreated))
+
+	require.Equal(t, 1, maxAttempts(nil))
+	require.Equal(t, 1, maxAttempts(&mapping.RetryDefinition{}))
+	require.Equal(t, 3, maxAttempts(&mapping.RetryDefinition{MaxAttempts: 3}))
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"maps"
+	"sync"
+)
+
+type scenariosKey struct{}
+
+type scenarios struct {
+	lock   sync.Mutex
+	states map[string]string
+}
+
+func (s *scenarios) state(name string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	state, found := s.states[name]
+	if !found {
+		return mapping.StartedState
+	}
+
+	return state
+}
+
+func (s *scenarios) transition(name string, state string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.states[name] = state
+}
+
+func (s *scenarios) all() map[string]string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return maps.Clone(s.states)
+}
+
+func (s *scenarios) reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.states = make(map[string]string)
+}
+
+func newScenarios() *scenarios {
+	return &scenarios{states: make(map[string]string)}
+}
+
+func withScenarios(ctx context.Context, store *scenarios) context.Context {
+	return context.WithValue(ctx, scenariosKey{}, store)
+}
+
+func scenarioState(ctx context.Context, name string) string {
+	store, ok := ctx.Value(scenariosKey{}).(*scenarios)
+	if !ok {
+		return mapping.StartedState
+	}
+
+	return store.state(name)
+}
+
+func transitionScenario(ctx context.Context, name string, state string) {
+	store, ok := ctx.Value(scenariosKey{}).(*scenarios)
+	if !ok {
+		return
+	}
+
+	store.transition(name, state)
+}
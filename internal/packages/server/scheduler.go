@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"github.com/djordjev/webhook-simulator/internal/packages/server/replacer"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var schedulerTick = 100 * time.Millisecond
+
+type Scheduler interface {
+	Start()
+}
+
+type scheduledRun struct {
+	cron     string
+	interval int
+	at       time.Time
+}
+
+type WebHookScheduler struct {
+	mapper     mapping.Mapper
+	httpClient HTTPClient
+	ctx        context.Context
+	runs       map[string]scheduledRun
+}
+
+func (s *WebHookScheduler) Start() {
+	ticker := time.NewTicker(schedulerTick)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				{
+					log.Println("shutdown signal received -> stop scheduled webhooks")
+					return
+				}
+
+			case <-ticker.C:
+				{
+					s.tick(now())
+				}
+			}
+		}
+	}()
+}
+
+func (s *WebHookScheduler) tick(current time.Time) {
+	active := make(map[string]bool)
+
+	for _, flow := range s.mapper.GetMappings() {
+		if flow.Schedule == nil {
+			continue
+		}
+
+		active[flow.ID] = true
+
+		run, found := s.runs[flow.ID]
+		if !found || run.cron != flow.Schedule.Cron || run.interval != flow.Schedule.Interval {
+			s.runs[flow.ID] = scheduledRun{
+				cron:     flow.Schedule.Cron,
+				interval: flow.Schedule.Interval,
+				at:       flow.Schedule.Next(current),
+			}
+
+			continue
+		}
+
+		if run.at.IsZero() || current.Before(run.at) {
+			continue
+		}
+
+		log.Println("triggering scheduled webhooks for " + flow.ID)
+
+		newDetachedResponder(&flow, nil, http.Header{}, url.Values{}, s.ctx, s.httpClient).triggerWebHooks()
+
+		run.at = flow.Schedule.Next(current)
+		s.runs[flow.ID] = run
+	}
+
+	for id := range s.runs {
+		if !active[id] {
+			delete(s.runs, id)
+		}
+	}
+}
+
+func newDetachedResponder(
+	flow *mapping.Flow,
+	body map[string]any,
+	header http.Header,
+	query url.Values,
+	ctx context.Context,
+	client HTTPClient,
+) RequestResponder {
+	if body == nil {
+		body = make(map[string]any)
+	}
+
+	request := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/", RawQuery: query.Encode()}, Header: header}
+
+	return RequestResponder{
+		request:    request,
+		flow:       flow,
+		body:       body,
+		mainCtx:    ctx,
+		httpClient: client,
+		replacer:   replacer.NewReplacer(body, header, map[string]string{}, query),
+	}
+}
+
+func NewScheduler(mapper mapping.Mapper, client HTTPClient, ctx context.Context) Scheduler {
+	return &WebHookScheduler{mapper: mapper, httpClient: client, ctx: ctx, runs: make(map[string]scheduledRun)}
+}
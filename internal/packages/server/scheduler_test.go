@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type clientFunc func(req *http.Request) (*http.Response, error)
+
+func (f clientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+var okClient = clientFunc(func(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+})
+
+func TestScheduler(t *testing.T) {
+	start := time.Date(2024, 10, 27, 20, 34, 58, 0, time.UTC)
+
+	heartbeat := mapping.Flow{
+		ID:       "heartbeat",
+		Schedule: &mapping.ScheduleDefinition{Interval: 1000},
+		WebHook:  &mapping.WebHookDefinition{Method: http.MethodPost, Path: "http://receiver/heartbeat", Body: map[string]any{"type": "ping"}},
+	}
+
+	renewal := mapping.Flow{
+		ID:       "renewal",
+		Schedule: &mapping.ScheduleDefinition{Cron: "0 21 * * *"},
+		WebHook:  &mapping.WebHookDefinition{Method: http.MethodPost, Path: "http://receiver/renewal"},
+	}
+
+	request := respondingFlow("request", 0, &mapping.RequestDefinition{Method: http.MethodGet, Path: "/"})
+
+	testCases := []struct {
+		name       string
+		ticks      []time.Duration
+		deliveries []string
+	}{
+		{
+			name:       "does not trigger before first run",
+			ticks:      []time.Duration{0, 500 * time.Millisecond},
+			deliveries: []string{},
+		},
+		{
+			name:       "triggers interval schedule repeatedly",
+			ticks:      []time.Duration{0, time.Second, 1500 * time.Millisecond, 2 * time.Second},
+			deliveries: []string{"http://receiver/heartbeat", "http://receiver/heartbeat"},
+		},
+		{
+			name:       "triggers cron schedule",
+			ticks:      []time.Duration{0, 25 * time.Minute, 26 * time.Minute},
+			deliveries: []string{"http://receiver/heartbeat", "http://receiver/heartbeat", "http://receiver/renewal"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			deliveries := newDeliveryLog(10)
+			client := recordingClient{client: okClient, log: deliveries}
+
+			scheduler := NewScheduler(newTestMapper([]mapping.Flow{heartbeat, renewal, request}), client, context.Background()).(*WebHookScheduler)
+
+			for _, tick := range test.ticks {
+				scheduler.tick(start.Add(tick))
+			}
+
+			require.Eventually(t, func() bool {
+				return len(deliveries.find(deliveryFilter{})) == len(test.deliveries)
+			}, time.Second, 10*time.Millisecond)
+
+			urls := make([]string, 0)
+			for _, delivery := range deliveries.find(deliveryFilter{}) {
+				urls = append(urls, delivery.URL)
+			}
+
+			require.ElementsMatch(t, test.deliveries, urls)
+		})
+	}
+}
+
+func TestSchedulerStopsWithContext(t *testing.T) {
+	original := schedulerTick
+	schedulerTick = time.Millisecond
+	defer func() {
+		schedulerTick = original
+	}()
+
+	deliveries := newDeliveryLog(100)
+	client := recordingClient{client: okClient, log: deliveries}
+
+	flow := mapping.Flow{
+		ID:       "heartbeat",
+		Schedule: &mapping.ScheduleDefinition{Interval: 1},
+		WebHook:  &mapping.WebHookDefinition{Method: http.MethodPost, Path: "http://receiver/heartbeat"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	NewScheduler(newTestMapper([]mapping.Flow{flow}), client, ctx).Start()
+
+	require.Eventually(t, func() bool {
+		return len(deliveries.find(deliveryFilter{})) > 0
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+
+	count := len(deliveries.find(deliveryFilter{}))
+	time.Sleep(20 * time.Millisecond)
+
+	require.Equal(t, count, len(deliveries.find(deliveryFilter{})))
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"math/rand"
+	"sync"
+)
+
+var randomResponse = rand.Intn
+
+type responseCounters struct {
+	lock   sync.Mutex
+	counts map[string]int
+}
+
+func (c *responseCounters) next(flow *mapping.Flow) *mapping.ResponseDefinition {
+	count := len(flow.Responses)
+	if count == 0 {
+		return flow.Response
+	}
+
+	if flow.ResponseMode == mapping.RandomMode {
+		return &flow.Responses[randomResponse(count)]
+	}
+
+	c.lock.Lock()
+	index := c.counts[flow.ID]
+	c.counts[flow.ID] = index + 1
+	c.lock.Unlock()
+
+	if flow.ResponseMode == mapping.CycleMode {
+		return &flow.Responses[index%count]
+	}
+
+	return &flow.Responses[min(index, count-1)]
+}
+
+func (c *responseCounters) reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.counts = make(map[string]int)
+}
+
+func newResponseCounters() *responseCounters {
+	return &responseCounters{counts: make(map[string]int)}
+}
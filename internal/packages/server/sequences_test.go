@@ -0,0 +1,98 @@
+package server
+
+import (
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestResponseCounters(t *testing.T) {
+	original := randomResponse
+	randomResponse = func(n int) int { return n - 1 }
+	defer func() {
+		randomResponse = original
+	}()
+
+	responses := []mapping.ResponseDefinition{
+		{Code: http.StatusAccepted},
+		{Code: http.StatusAccepted},
+		{Code: http.StatusOK},
+	}
+
+	testCases := []struct {
+		name  string
+		flow  mapping.Flow
+		codes []int
+	}{
+		{
+			name:  "uses single response",
+			flow:  mapping.Flow{ID: "single", Response: &mapping.ResponseDefinition{Code: http.StatusCreated}},
+			codes: []int{http.StatusCreated, http.StatusCreated},
+		},
+		{
+			name:  "stops at last response in sequence",
+			flow:  mapping.Flow{ID: "sequence", Responses: responses},
+			codes: []int{http.StatusAccepted, http.StatusAccepted, http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:  "cycles through responses",
+			flow:  mapping.Flow{ID: "cycle", Responses: responses, ResponseMode: mapping.CycleMode},
+			codes: []int{http.StatusAccepted, http.StatusAccepted, http.StatusOK, http.StatusAccepted, http.StatusAccepted},
+		},
+		{
+			name:  "picks random response",
+			flow:  mapping.Flow{ID: "random", Responses: responses, ResponseMode: mapping.RandomMode},
+			codes: []int{http.StatusOK, http.StatusOK},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			counters := newResponseCounters()
+
+			codes := make([]int, 0)
+			for range test.codes {
+				codes = append(codes, counters.next(&test.flow).Code)
+			}
+
+			require.Equal(t, test.codes, codes)
+		})
+	}
+}
+
+func TestResponseCountersConcurrently(t *testing.T) {
+	responses := make([]mapping.ResponseDefinition, 100)
+	for i := range responses {
+		responses[i] = mapping.ResponseDefinition{Code: i}
+	}
+
+	flow := mapping.Flow{ID: "concurrent", Responses: responses}
+	counters := newResponseCounters()
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	seen := make(map[int]bool)
+
+	for range len(responses) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			code := counters.next(&flow).Code
+
+			lock.Lock()
+			seen[code] = true
+			lock.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	require.Len(t, seen, len(responses))
+
+	counters.reset()
+	require.Equal(t, 0, counters.next(&flow).Code)
+}
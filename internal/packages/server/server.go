@@ -2,23 +2,34 @@ package server
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/djordjev/webhook-simulator/internal/packages/config"
 	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
 	"log"
 	"maps"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
-	"sync/atomic"
 )
 
+type Server interface {
+	http.Handler
+	Scheduler() Scheduler
+}
+
 type server struct {
 	config          config.Config
 	mapper          mapping.Mapper
 	matchBuilder    MatchBuilder
 	responseBuilder ResponseBuilder
 	appCtx          context.Context
+	admin           http.Handler
+	journal         *journal
+	httpClient      HTTPClient
+	scheduler       Scheduler
+	scenarios       *scenarios
+	counters        *responseCounters
 }
 
 func (s server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
@@ -36,63 +47,114 @@ func (s server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 		}
 	}
 
-	payload := make(map[string]any)
+	if strings.HasPrefix(request.URL.Path, AdminPrefix) {
+		s.admin.ServeHTTP(writer, request)
+		return
+	}
+
+	request = request.WithContext(withScenarios(request.Context(), s.scenarios))
 
-	err := json.NewDecoder(request.Body).Decode(&payload)
+	payload, textual, err := parseBody(request)
 	if err != nil {
 		writer.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	mappings := s.mapper.GetMappings()
+	request = request.WithContext(withTextualBody(request.Context(), textual))
+
+	mappings := prioritize(requestFlows(s.mapper.GetMappings()))
 	if len(mappings) == 0 {
-		writer.WriteHeader(http.StatusNoContent)
+		s.journal.record(request, payload, "")
+		s.respondUnmatched(writer, newDiagnostic(request, []nearMiss{}))
 		return
 	}
 
 	var wg sync.WaitGroup
-	var counter atomic.Int32
 
-	for _, current := range mappings {
+	matched := make([]bool, len(mappings))
+	bodies := make([]map[string]any, len(mappings))
+	matchers := make([]Matcher, len(mappings))
+
+	for index := range mappings {
 		body := make(map[string]any)
 		maps.Copy(body, payload)
+		bodies[index] = body
 
-		matcher := s.matchBuilder(request, &current, body)
+		matcher := s.matchBuilder(request, &mappings[index], body)
+		matchers[index] = matcher
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
 			matcher.Match()
 
-			if !matcher.IsMatch() {
-				return
-			}
+			matched[index] = matcher.IsMatch()
+		}()
+	}
+
+	wg.Wait()
+
+	winner := slices.Index(matched, true)
+	if winner < 0 {
+		s.journal.record(request, payload, "")
+		s.respondUnmatched(writer, newDiagnostic(request, findNearMisses(mappings, matchers)))
+		return
+	}
 
-			if res := counter.Swap(1); res > 0 {
-				log.Println("multiple matchers are matching this request. Ignoring...")
-				return
-			}
+	current := &mappings[winner]
+	current.Response = s.counters.next(current)
+	s.journal.record(request, payload, current.ID)
 
-			log.Println(fmt.Sprintf("request matched %s %s", current.Request.Method, current.Request.Path))
+	if count := len(slices.DeleteFunc(slices.Clone(matched), func(isMatch bool) bool { return !isMatch })); count > 1 {
+		log.Println(fmt.Sprintf("%d mappings are matching this request. Using %s", count, current.ID))
+	}
 
-			responder := s.responseBuilder(
-				request,
-				&current,
-				body,
-				writer,
-				s.appCtx,
-				http.DefaultClient,
-			)
+	log.Println(fmt.Sprintf("request matched %s (%s %s)", current.ID, current.Request.Method, current.Request.Path))
 
-			responder.Respond()
+	responder := s.responseBuilder(
+		request,
+		current,
+		bodies[winner],
+		writer,
+		s.appCtx,
+		s.httpClient,
+	)
 
-		}()
+	responder.Respond()
+}
+
+func (s server) Scheduler() Scheduler {
+	return s.scheduler
+}
+
+func requestFlows(flows []mapping.Flow) []mapping.Flow {
+	return slices.DeleteFunc(slices.Clone(flows), func(flow mapping.Flow) bool {
+		return flow.Request == nil
+	})
+}
+
+func (s server) respondUnmatched(writer http.ResponseWriter, report diagnostic) {
+	log.Println(report.String())
+
+	body := []byte(s.config.UnmatchedBody)
+	if s.config.UnmatchedBody == "" {
+		body = report.Marshal()
+		writer.Header().Set("Content-Type", "application/json")
 	}
 
-	wg.Wait()
+	for k, v := range s.config.UnmatchedHeaders {
+		writer.Header().Set(k, v)
+	}
 
-	if counter.Load() == 0 {
-		writer.WriteHeader(http.StatusBadRequest)
+	code := s.config.UnmatchedCode
+	if code == 0 {
+		code = http.StatusNotFound
+	}
+
+	writer.WriteHeader(code)
+	_, err := writer.Write(body)
+	if err != nil {
+		log.Println("unable to send unmatched response")
 	}
 }
 
@@ -102,13 +164,26 @@ func NewServer(
 	matchBuilder MatchBuilder,
 	responseBuilder ResponseBuilder,
 	appCtx context.Context,
-) http.Handler {
+) Server {
+	requests := newJournal(cfg.JournalSize)
+	deliveries := newDeliveryLog(cfg.JournalSize)
+	states := newScenarios()
+	counters := newResponseCounters()
+
+	client := recordingClient{client: newWebHookClient(cfg.WebHookClient), log: deliveries}
+
 	srv := server{
 		config:          cfg,
 		mapper:          mapper,
 		matchBuilder:    matchBuilder,
 		responseBuilder: responseBuilder,
 		appCtx:          appCtx,
+		admin:           newAdmin(mapper, requests, deliveries, states, counters, client, appCtx),
+		journal:         requests,
+		httpClient:      client,
+		scheduler:       NewScheduler(mapper, client, appCtx),
+		scenarios:       states,
+		counters:        counters,
 	}
 
 	return srv
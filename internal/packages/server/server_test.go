@@ -0,0 +1,431 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"github.com/djordjev/webhook-simulator/internal/packages/config"
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestMapper(flows []mapping.Flow) mapping.Mapper {
+	mapper := mapping.NewMapping(config.Config{}, fstest.MapFS{})
+
+	for _, flow := range flows {
+		_, _ = mapper.AddMapping(flow)
+	}
+
+	return mapper
+}
+
+func respondingFlow(id string, priority int, request *mapping.RequestDefinition) mapping.Flow {
+	return mapping.Flow{
+		ID:       id,
+		Priority: priority,
+		Request:  request,
+		Response: &mapping.ResponseDefinition{
+			Code: http.StatusOK,
+			Body: map[string]any{"flow": id},
+		},
+	}
+}
+
+func withFallback(flow mapping.Flow) mapping.Flow {
+	flow.Fallback = true
+	return flow
+}
+
+func TestServeHTTP(t *testing.T) {
+	genericUsers := &mapping.RequestDefinition{Method: http.MethodGet, Path: "/users/{id}"}
+	specificUser := &mapping.RequestDefinition{Method: http.MethodGet, Path: "/users/me"}
+	anyPath := &mapping.RequestDefinition{Method: http.MethodGet, Path: "/**"}
+
+	testCases := []struct {
+		name            string
+		cfg             config.Config
+		flows           []mapping.Flow
+		method          string
+		path            string
+		body            string
+		expectedCode    int
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:         "responds with not found when there are no mappings",
+			flows:        []mapping.Flow{},
+			method:       http.MethodGet,
+			path:         "/users",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{
+				"error": "no mapping matched the request",
+				"request": {"method": "GET", "path": "/users", "query": ""},
+				"nearMisses": []
+			}`,
+			expectedHeaders: map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name:         "responds with not found when nothing matches",
+			flows:        []mapping.Flow{respondingFlow("a", 0, specificUser)},
+			method:       http.MethodGet,
+			path:         "/orders?page=1",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{
+				"error": "no mapping matched the request",
+				"request": {"method": "GET", "path": "/orders", "query": "page=1"},
+				"nearMisses": [
+					{"id": "a", "method": "GET", "path": "/users/me", "mismatches": ["path: expected /users/me but got /orders"]}
+				]
+			}`,
+			expectedHeaders: map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name: "responds with configured unmatched response",
+			cfg: config.Config{
+				UnmatchedCode:    http.StatusTeapot,
+				UnmatchedBody:    `{"error": "unmatched"}`,
+				UnmatchedHeaders: map[string]string{"Content-Type": "application/json"},
+			},
+			flows:           []mapping.Flow{respondingFlow("a", 0, specificUser)},
+			method:          http.MethodGet,
+			path:            "/orders",
+			expectedCode:    http.StatusTeapot,
+			expectedBody:    `{"error": "unmatched"}`,
+			expectedHeaders: map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name: "uses fallback flow when nothing else matches",
+			flows: []mapping.Flow{
+				withFallback(respondingFlow("a", 100, &mapping.RequestDefinition{Path: "*"})),
+				respondingFlow("b", 0, specificUser),
+			},
+			method:       http.MethodPost,
+			path:         "/orders/1",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"flow": "a"}`,
+		},
+		{
+			name: "does not use fallback flow when other flow matches",
+			flows: []mapping.Flow{
+				withFallback(respondingFlow("a", 100, &mapping.RequestDefinition{Path: "*"})),
+				respondingFlow("b", 0, specificUser),
+			},
+			method:       http.MethodGet,
+			path:         "/users/me",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"flow": "b"}`,
+		},
+		{
+			name:         "matches request without body",
+			flows:        []mapping.Flow{respondingFlow("a", 0, specificUser)},
+			method:       http.MethodGet,
+			path:         "/users/me",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"flow": "a"}`,
+		},
+		{
+			name: "uses flow with higher priority",
+			flows: []mapping.Flow{
+				respondingFlow("a", 0, specificUser),
+				respondingFlow("b", 5, anyPath),
+			},
+			method:       http.MethodGet,
+			path:         "/users/me",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"flow": "b"}`,
+		},
+		{
+			name: "uses more specific flow when priorities are equal",
+			flows: []mapping.Flow{
+				respondingFlow("a", 0, anyPath),
+				respondingFlow("b", 0, genericUsers),
+				respondingFlow("c", 0, specificUser),
+			},
+			method:       http.MethodGet,
+			path:         "/users/me",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"flow": "c"}`,
+		},
+		{
+			name: "uses flow with lower id when flows are equally specific",
+			flows: []mapping.Flow{
+				respondingFlow("b", 0, genericUsers),
+				respondingFlow("a", 0, genericUsers),
+			},
+			method:       http.MethodGet,
+			path:         "/users/me",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"flow": "a"}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			srv := NewServer(
+				test.cfg,
+				newTestMapper(test.flows),
+				RequestMatchBuilder,
+				RequestResponseBuilder,
+				context.Background(),
+			)
+
+			for range 10 {
+				request := httptest.NewRequest(test.method, test.path, bytes.NewBufferString(test.body))
+				response := httptest.NewRecorder()
+
+				srv.ServeHTTP(response, request)
+
+				require.Equal(t, test.expectedCode, response.Code)
+				for k, v := range test.expectedHeaders {
+					require.Equal(t, v, response.Header().Get(k))
+				}
+
+				if test.expectedBody == "" {
+					require.Empty(t, response.Body.String())
+				} else {
+					require.JSONEq(t, test.expectedBody, response.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestServeHTTPUnmatchedHeaders(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         config.Config
+		contentType string
+	}{
+		{
+			name:        "sends near-miss report as JSON",
+			contentType: "application/json",
+		},
+		{
+			name:        "does not label custom body as JSON",
+			cfg:         config.Config{UnmatchedBody: "not mocked"},
+			contentType: "",
+		},
+		{
+			name:        "applies configured headers over near-miss report headers",
+			cfg:         config.Config{UnmatchedHeaders: map[string]string{"Content-Type": "application/problem+json"}},
+			contentType: "application/problem+json",
+		},
+		{
+			name: "applies configured headers to custom body",
+			cfg: config.Config{
+				UnmatchedBody:    "not mocked",
+				UnmatchedHeaders: map[string]string{"Content-Type": "text/plain"},
+			},
+			contentType: "text/plain",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			srv := NewServer(test.cfg, newTestMapper([]mapping.Flow{}), RequestMatchBuilder, RequestResponseBuilder, context.Background())
+
+			response := httptest.NewRecorder()
+			srv.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+			require.Equal(t, http.StatusNotFound, response.Code)
+			require.Equal(t, test.contentType, response.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestSpecificity(t *testing.T) {
+	testCases := []struct {
+		name         string
+		moreSpecific *mapping.RequestDefinition
+		lessSpecific *mapping.RequestDefinition
+	}{
+		{
+			name:         "literal path is more specific than path parameter",
+			moreSpecific: &mapping.RequestDefinition{Path: "/users/me"},
+			lessSpecific: &mapping.RequestDefinition{Path: "/users/{id}"},
+		},
+		{
+			name:         "path parameter is more specific than wildcard",
+			moreSpecific: &mapping.RequestDefinition{Path: "/users/{id}"},
+			lessSpecific: &mapping.RequestDefinition{Path: "/users/*"},
+		},
+		{
+			name:         "method makes definition more specific",
+			moreSpecific: &mapping.RequestDefinition{Method: http.MethodGet, Path: "/users"},
+			lessSpecific: &mapping.RequestDefinition{Path: "/users"},
+		},
+		{
+			name:         "body constraints make definition more specific",
+			moreSpecific: &mapping.RequestDefinition{Path: "/users", Body: map[string]any{"user": map[string]any{"name": "Jon", "age": 35}}},
+			lessSpecific: &mapping.RequestDefinition{Path: "/users", Body: map[string]any{"user": map[string]any{"name": "Jon"}}},
+		},
+		{
+			name:         "headers and query make definition more specific",
+			moreSpecific: &mapping.RequestDefinition{Path: "/users", Headers: map[string]any{"x-api-key": "abc"}, Query: map[string]any{"q": "a"}},
+			lessSpecific: &mapping.RequestDefinition{Path: "/users", Headers: map[string]any{"x-api-key": "abc"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			require.Greater(t, specificity(test.moreSpecific), specificity(test.lessSpecific))
+		})
+	}
+}
+
+type stubMatcher struct {
+	mismatches []string
+}
+
+func (s stubMatcher) Match() {}
+
+func (s stubMatcher) IsMatch() bool {
+	return len(s.mismatches) == 0
+}
+
+func (s stubMatcher) Mismatches() []string {
+	return s.mismatches
+}
+
+func TestFindNearMisses(t *testing.T) {
+	flows := []mapping.Flow{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	matchers := []Matcher{
+		stubMatcher{mismatches: []string{"method", "path", "body"}},
+		stubMatcher{mismatches: []string{"body"}},
+		stubMatcher{mismatches: []string{"method", "path"}},
+		stubMatcher{mismatches: []string{"header"}},
+	}
+
+	nearMisses := findNearMisses(flows, matchers)
+
+	require.Equal(t, []nearMiss{
+		{ID: "b", Mismatches: []string{"body"}},
+		{ID: "d", Mismatches: []string{"header"}},
+		{ID: "c", Mismatches: []string{"method", "path"}},
+	}, nearMisses)
+}
+
+func TestServeHTTPScenarios(t *testing.T) {
+	order := &mapping.RequestDefinition{Method: http.MethodGet, Path: "/orders/1"}
+
+	pending := respondingFlow("pending", 0, order)
+	pending.Scenario = "approval"
+	pending.RequiredState = mapping.StartedState
+	pending.Response.Body = map[string]any{"status": "pending"}
+
+	approve := respondingFlow("approve", 0, &mapping.RequestDefinition{Method: http.MethodPost, Path: "/orders/1/approve"})
+	approve.Scenario = "approval"
+	approve.NewState = "approved"
+
+	approved := respondingFlow("approved", 0, order)
+	approved.Scenario = "approval"
+	approved.RequiredState = "approved"
+	approved.Response.Body = map[string]any{"status": "approved"}
+
+	srv := NewServer(config.Config{}, newTestMapper([]mapping.Flow{pending, approve, approved}), RequestMatchBuilder, RequestResponseBuilder, context.Background())
+
+	call := func(method string, path string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		srv.ServeHTTP(response, httptest.NewRequest(method, path, nil))
+
+		return response
+	}
+
+	require.JSONEq(t, `{"status": "pending"}`, call(http.MethodGet, "/orders/1").Body.String())
+	require.JSONEq(t, `{"status": "pending"}`, call(http.MethodGet, "/orders/1").Body.String())
+
+	require.Equal(t, http.StatusOK, call(http.MethodPost, "/orders/1/approve").Code)
+
+	require.JSONEq(t, `{"status": "approved"}`, call(http.MethodGet, "/orders/1").Body.String())
+	require.JSONEq(t, `{"approval": "approved"}`, call(http.MethodGet, "/__admin/scenarios").Body.String())
+
+	require.Equal(t, http.StatusNoContent, call(http.MethodDelete, "/__admin/scenarios").Code)
+	require.JSONEq(t, `{"status": "pending"}`, call(http.MethodGet, "/orders/1").Body.String())
+}
+
+func TestServeHTTPResponseSequence(t *testing.T) {
+	polling := mapping.Flow{
+		ID:      "polling",
+		Request: &mapping.RequestDefinition{Method: http.MethodGet, Path: "/jobs/1"},
+		Responses: []mapping.ResponseDefinition{
+			{Code: http.StatusAccepted, Body: map[string]any{"status": "running"}},
+			{Code: http.StatusAccepted, Body: map[string]any{"status": "running"}},
+			{Code: http.StatusOK, Body: map[string]any{"status": "done"}},
+		},
+	}
+
+	srv := NewServer(config.Config{}, newTestMapper([]mapping.Flow{polling}), RequestMatchBuilder, RequestResponseBuilder, context.Background())
+
+	call := func(method string, path string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		srv.ServeHTTP(response, httptest.NewRequest(method, path, nil))
+
+		return response
+	}
+
+	codes := make([]int, 0)
+	for range 4 {
+		codes = append(codes, call(http.MethodGet, "/jobs/1").Code)
+	}
+
+	require.Equal(t, []int{http.StatusAccepted, http.StatusAccepted, http.StatusOK, http.StatusOK}, codes)
+
+	require.Equal(t, http.StatusNoContent, call(http.MethodDelete, "/__admin/sequences").Code)
+
+	first := call(http.MethodGet, "/jobs/1")
+	require.Equal(t, http.StatusAccepted, first.Code)
+	require.JSONEq(t, `{"status": "running"}`, first.Body.String())
+}
+
+func TestServeHTTPEmptyResponseSequence(t *testing.T) {
+	fileSystem := fstest.MapFS{
+		"empty.whs": {Data: []byte(`{ "request": { "method": "GET", "path": "/jobs/1" }, "responses": [] }`)},
+	}
+
+	mapper := mapping.NewMapping(config.Config{}, fileSystem)
+	_ = mapper.Refresh()
+
+	srv := NewServer(config.Config{}, mapper, RequestMatchBuilder, RequestResponseBuilder, context.Background())
+
+	call := func(method string, path string, body string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		srv.ServeHTTP(response, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+
+		return response
+	}
+
+	require.Equal(t, http.StatusNotFound, call(http.MethodGet, "/jobs/1", "").Code)
+
+	created := call(http.MethodPost, "/__admin/mappings", `{ "request": { "method": "GET", "path": "/jobs/2" }, "responses": [] }`)
+	require.Equal(t, http.StatusBadRequest, created.Code)
+	require.Equal(t, http.StatusNotFound, call(http.MethodGet, "/jobs/2", "").Code)
+}
+
+func TestServeHTTPBodyValueTypes(t *testing.T) {
+	flow := respondingFlow("payment", 0, &mapping.RequestDefinition{
+		Method: http.MethodPost,
+		Path:   "/payments",
+		Body:   map[string]any{"amount": float64(100), "paid": true},
+	})
+
+	srv := NewServer(config.Config{}, newTestMapper([]mapping.Flow{flow}), RequestMatchBuilder, RequestResponseBuilder, context.Background())
+
+	call := func(contentType string, body string) int {
+		request := httptest.NewRequest(http.MethodPost, "/payments", bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", contentType)
+
+		response := httptest.NewRecorder()
+		srv.ServeHTTP(response, request)
+
+		return response.Code
+	}
+
+	require.Equal(t, http.StatusOK, call("application/json", `{"amount": 100, "paid": true}`))
+	require.Equal(t, http.StatusNotFound, call("application/json", `{"amount": "100", "paid": true}`))
+	require.Equal(t, http.StatusNotFound, call("application/json", `{"amount": 100, "paid": "true"}`))
+	require.Equal(t, http.StatusOK, call("application/x-www-form-urlencoded", "amount=100&paid=true"))
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultSignatureHeader = "X-Signature"
+const DefaultSignatureFormat = "{signature}"
+
+var signingHashes = map[string]func() hash.Hash{
+	mapping.SHA1:   sha1.New,
+	mapping.SHA256: sha256.New,
+	mapping.SHA512: sha512.New,
+}
+
+func signatureHeaders(signing *mapping.SigningDefinition, payload []byte, timestamp time.Time) (map[string]string, error) {
+	algorithm := signing.Algorithm
+	if algorithm == "" {
+		algorithm = mapping.SHA256
+	}
+
+	newHash, ok := signingHashes[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing algorithm %s", algorithm)
+	}
+
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(newHash, []byte(signing.Secret))
+	if signing.Timestamp {
+		mac.Write([]byte(unix + "."))
+	}
+	mac.Write(payload)
+
+	var signature string
+	if signing.Encoding == mapping.Base64Encoding {
+		signature = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	} else {
+		signature = hex.EncodeToString(mac.Sum(nil))
+	}
+
+	format := signing.Format
+	if format == "" {
+		format = DefaultSignatureFormat
+	}
+
+	header := signing.Header
+	if header == "" {
+		header = DefaultSignatureHeader
+	}
+
+	value := strings.NewReplacer("{timestamp}", unix, "{signature}", signature).Replace(format)
+
+	headers := map[string]string{header: value}
+	if signing.TimestampHeader != "" {
+		headers[signing.TimestampHeader] = unix
+	}
+
+	return headers, nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"github.com/djordjev/webhook-simulator/internal/packages/mapping"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSignatureHeaders(t *testing.T) {
+	payload := []byte(`{"id":1}`)
+	timestamp := time.Unix(1700000000, 0)
+
+	testCases := []struct {
+		name    string
+		signing mapping.SigningDefinition
+		headers map[string]string
+	}{
+		{
+			name:    "signs with defaults",
+			signing: mapping.SigningDefinition{Secret: "secret"},
+			headers: map[string]string{
+				"X-Signature": "03def589620c813f198fd03d7967e292b163ef0435ebf43071ce0e9519763cb7",
+			},
+		},
+		{
+			name:    "uses header and format",
+			signing: mapping.SigningDefinition{Secret: "secret", Algorithm: mapping.SHA1, Header: "X-Hub-Signature", Format: "sha1={signature}"},
+			headers: map[string]string{
+				"X-Hub-Signature": "sha1=a64abd01291976b35debd870ad13a30c94343831",
+			},
+		},
+		{
+			name:    "encodes signature as base64",
+			signing: mapping.SigningDefinition{Secret: "secret", Algorithm: mapping.SHA512, Encoding: mapping.Base64Encoding},
+			headers: map[string]string{
+				"X-Signature": "kHDE5ECFec8FxL3+YSczG5Bt10KWW4etCspqDAaYreLts1wTYUIZIIo+inPWeqa85o+b5qHEjlHWj75VeDKngQ==",
+			},
+		},
+		{
+			name: "signs timestamp prefix",
+			signing: mapping.SigningDefinition{
+				Secret:          "secret",
+				Header:          "Stripe-Signature",
+				Format:          "t={timestamp},v1={signature}",
+				Timestamp:       true,
+				TimestampHeader: "X-Timestamp",
+			},
+			headers: map[string]string{
+				"Stripe-Signature": "t=1700000000,v1=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11",
+				"X-Timestamp":      "1700000000",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			headers, err := signatureHeaders(&test.signing, payload, timestamp)
+
+			require.NoError(t, err)
+			require.Equal(t, test.headers, headers)
+		})
+	}
+
+	_, err := signatureHeaders(&mapping.SigningDefinition{Secret: "secret", Algorithm: "md5"}, payload, timestamp)
+	require.Error(t, err)
+}
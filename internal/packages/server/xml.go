@@ -0,0 +1,186 @@
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const XMLText = "#text"
+const XMLAttributePrefix = "@"
+const xmlTextSelector = "text()"
+
+type xmlElement struct {
+	name     string
+	fields   map[string]any
+	hasChild bool
+	text     strings.Builder
+}
+
+func newXMLElement(start xml.StartElement) *xmlElement {
+	element := &xmlElement{name: start.Name.Local, fields: make(map[string]any)}
+
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+
+		element.fields[XMLAttributePrefix+attr.Name.Local] = attr.Value
+	}
+
+	return element
+}
+
+func (e *xmlElement) addChild(name string, value any) {
+	e.hasChild = true
+
+	existing, found := e.fields[name]
+	if !found {
+		e.fields[name] = value
+		return
+	}
+
+	if elements, ok := existing.([]any); ok {
+		e.fields[name] = append(elements, value)
+	} else {
+		e.fields[name] = []any{existing, value}
+	}
+}
+
+func (e *xmlElement) value() any {
+	text := strings.TrimSpace(e.text.String())
+
+	if len(e.fields) == 0 {
+		return text
+	}
+
+	if text != "" {
+		e.fields[XMLText] = text
+	}
+
+	return e.fields
+}
+
+func isXMLMediaType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+func parseXML(data []byte) (map[string]any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var stack []*xmlElement
+	var root map[string]any
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			{
+				if root != nil {
+					return nil, errors.New("multiple root elements")
+				}
+
+				stack = append(stack, newXMLElement(t))
+			}
+
+		case xml.CharData:
+			{
+				if len(stack) > 0 {
+					stack[len(stack)-1].text.Write(t)
+				}
+			}
+
+		case xml.EndElement:
+			{
+				current := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+
+				if len(stack) == 0 {
+					root = map[string]any{current.name: current.value()}
+				} else {
+					stack[len(stack)-1].addChild(current.name, current.value())
+				}
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("no root element")
+	}
+
+	return root, nil
+}
+
+func isXPathSelector(key string) bool {
+	return strings.HasPrefix(key, "/")
+}
+
+func selectXPath(object map[string]any, selector string) (any, bool) {
+	var current any = object
+
+	for _, segment := range strings.Split(strings.TrimPrefix(selector, "/"), "/") {
+		if segment == xmlTextSelector {
+			if text, ok := current.(string); ok {
+				return text, true
+			}
+
+			currentMap, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+
+			text, found := currentMap[XMLText]
+			return text, found
+		}
+
+		currentMap, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		name, index := xpathSegment(segment)
+
+		value, found := currentMap[name]
+		if !found {
+			return nil, false
+		}
+
+		elements, isArray := value.([]any)
+		if !isArray {
+			elements = []any{value}
+		}
+
+		if index < 0 || index >= len(elements) {
+			return nil, false
+		}
+
+		current = elements[index]
+	}
+
+	return current, true
+}
+
+func xpathSegment(segment string) (string, int) {
+	start := strings.Index(segment, "[")
+	if start < 0 || !strings.HasSuffix(segment, "]") {
+		return segment, 0
+	}
+
+	position, err := strconv.Atoi(segment[start+1 : len(segment)-1])
+	if err != nil {
+		return segment, -1
+	}
+
+	return segment[:start], position - 1
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+var soapPayment = `
+	<?xml version="1.0" encoding="UTF-8"?>
+	<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+		<soap:Body>
+			<Payment id="p1" currency="EUR">
+				<Amount>150</Amount>
+				<Item sku="a">First</Item>
+				<Item sku="b">Second</Item>
+			</Payment>
+		</soap:Body>
+	</soap:Envelope>
+`
+
+func TestParseXML(t *testing.T) {
+	t.Run("maps elements and attributes", func(t *testing.T) {
+		parsed, err := parseXML([]byte(soapPayment))
+
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"Envelope": map[string]any{
+				"Body": map[string]any{
+					"Payment": map[string]any{
+						"@id":       "p1",
+						"@currency": "EUR",
+						"Amount":    "150",
+						"Item": []any{
+							map[string]any{"@sku": "a", "#text": "First"},
+							map[string]any{"@sku": "b", "#text": "Second"},
+						},
+					},
+				},
+			},
+		}, parsed)
+	})
+
+	t.Run("fails on malformed document", func(t *testing.T) {
+		_, err := parseXML([]byte("<Payment><Amount></Payment>"))
+
+		require.Error(t, err)
+	})
+
+	t.Run("fails on content without elements", func(t *testing.T) {
+		_, err := parseXML([]byte("plain text"))
+
+		require.Error(t, err)
+	})
+}
+
+func TestSelectXPath(t *testing.T) {
+	parsed, _ := parseXML([]byte(soapPayment))
+
+	testCases := []struct {
+		name     string
+		selector string
+		found    bool
+		value    any
+	}{
+		{
+			name:     "selects element text",
+			selector: "/Envelope/Body/Payment/Amount",
+			found:    true,
+			value:    "150",
+		},
+		{
+			name:     "selects attribute",
+			selector: "/Envelope/Body/Payment/@currency",
+			found:    true,
+			value:    "EUR",
+		},
+		{
+			name:     "selects repeated element by position",
+			selector: "/Envelope/Body/Payment/Item[2]/@sku",
+			found:    true,
+			value:    "b",
+		},
+		{
+			name:     "selects first repeated element without position",
+			selector: "/Envelope/Body/Payment/Item/text()",
+			found:    true,
+			value:    "First",
+		},
+		{
+			name:     "selects text of simple element",
+			selector: "/Envelope/Body/Payment/Amount/text()",
+			found:    true,
+			value:    "150",
+		},
+		{
+			name:     "does not find missing element",
+			selector: "/Envelope/Body/Refund",
+			found:    false,
+		},
+		{
+			name:     "does not find element out of range",
+			selector: "/Envelope/Body/Payment/Item[3]",
+			found:    false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			value, found := selectXPath(parsed, test.selector)
+
+			require.Equal(t, test.found, found)
+			if test.found {
+				require.Equal(t, test.value, value)
+			}
+		})
+	}
+}